@@ -0,0 +1,401 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	v1 "github.com/BaileyJM02/unb-api-go/v1"
+)
+
+// commands maps each top-level command to the function that runs it with
+// the arguments after its name.
+var commands = map[string]func(c *cli, ctx context.Context, args []string) error{
+	"check":       (*cli).check,
+	"balance":     (*cli).balance,
+	"leaderboard": (*cli).leaderboard,
+	"export":      (*cli).export,
+	"items":       (*cli).items,
+	"inventory":   (*cli).inventory,
+}
+
+// flags returns a flag set for a command, with the -json flag.
+func (c *cli) flags(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "usage: unb %s %s\n", name, usage)
+		fs.PrintDefaults()
+	}
+	c.jsonFlag(fs)
+	return fs
+}
+
+// parse parses args with fs and checks that n arguments are left.
+func (c *cli) parse(fs *flag.FlagSet, args []string, n int) error {
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return err
+		}
+		return errUsage
+	}
+	if fs.NArg() != n {
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+// subcommand runs the subcommand of a command named in args[0].
+func (c *cli) subcommand(ctx context.Context, name string, args []string, subs map[string]func(c *cli, ctx context.Context, args []string) error) error {
+	if len(args) > 0 {
+		if sub, ok := subs[args[0]]; ok {
+			return sub(c, ctx, args[1:])
+		}
+		fmt.Fprintf(c.stderr, "unb: unknown command %q\n", name+" "+args[0])
+	}
+	fmt.Fprintf(c.stderr, "usage: unb %s <command>\n\ncommands:\n", name)
+	for _, sub := range sortedKeys(subs) {
+		fmt.Fprintf(c.stderr, "  %s %s\n", name, sub)
+	}
+	return errUsage
+}
+
+func (c *cli) check(ctx context.Context, args []string) error {
+	fs := c.flags("check", "")
+	if err := c.parse(fs, args, 0); err != nil {
+		return err
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+	status, err := api.CheckContext(ctx)
+	out := struct {
+		Up     bool  `json:"up"`
+		PingMS int64 `json:"ping_ms"`
+	}{status.Up, status.Ping.Milliseconds()}
+	if printErr := c.print(out, func(w io.Writer) {
+		row(w, "STATUS", "PING")
+		if status.Up {
+			row(w, "up", status.Ping.Round(time.Millisecond))
+		} else {
+			row(w, "down", "-")
+		}
+	}); printErr != nil {
+		return printErr
+	}
+	return err
+}
+
+func (c *cli) balance(ctx context.Context, args []string) error {
+	return c.subcommand(ctx, "balance", args, map[string]func(c *cli, ctx context.Context, args []string) error{
+		"get": (*cli).balanceGet,
+		"set": func(c *cli, ctx context.Context, args []string) error { return c.balanceModify(ctx, "set", args) },
+		"add": func(c *cli, ctx context.Context, args []string) error { return c.balanceModify(ctx, "add", args) },
+	})
+}
+
+func (c *cli) balanceGet(ctx context.Context, args []string) error {
+	fs := c.flags("balance get", "<guild> <user>")
+	if err := c.parse(fs, args, 2); err != nil {
+		return err
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+	bal, err := api.GetBalanceContext(ctx, fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	return c.printBalance(bal)
+}
+
+// amountFlag is a flag holding an Amount, which remembers whether it was
+// given.
+type amountFlag struct {
+	v1.Amount
+	set bool
+}
+
+func (f *amountFlag) Set(s string) error {
+	a, err := v1.ParseAmount(s)
+	if err != nil {
+		return err
+	}
+	f.Amount, f.set = a, true
+	return nil
+}
+
+// balanceModify runs "balance set" or "balance add".
+func (c *cli) balanceModify(ctx context.Context, mode string, args []string) error {
+	fs := c.flags("balance "+mode, "[flags] <guild> <user>")
+	var cash, bank amountFlag
+	verb := "set the"
+	if mode == "add" {
+		verb = "add to the"
+	}
+	fs.Var(&cash, "cash", "amount to "+verb+" user's cash (a whole number or Infinity)")
+	fs.Var(&bank, "bank", "amount to "+verb+" user's bank (a whole number or Infinity)")
+	reason := fs.String("reason", "", "reason shown in the guild's audit log")
+	key := fs.String("key", "", "idempotency key: rerunning the command with the same key within a week doesn't apply it again")
+	if err := c.parse(fs, args, 2); err != nil {
+		return err
+	}
+	if !cash.set && !bank.set {
+		fmt.Fprintln(c.stderr, "unb: give -cash and/or -bank")
+		fs.Usage()
+		return errUsage
+	}
+
+	update := v1.NewBalanceUpdate().Reason(*reason).IdempotencyKey(*key)
+	if cash.set {
+		if mode == "add" {
+			update.AddCash(cash.Amount)
+		} else {
+			update.SetCash(cash.Amount)
+		}
+	}
+	if bank.set {
+		if mode == "add" {
+			update.AddBank(bank.Amount)
+		} else {
+			update.SetBank(bank.Amount)
+		}
+	}
+	var opts []v1.Option
+	if *key != "" {
+		store, err := c.idempotencyStore()
+		if err != nil {
+			return err
+		}
+		opts = append(opts, v1.WithIdempotencyStore(store))
+	}
+	api, err := c.client(opts...)
+	if err != nil {
+		return err
+	}
+	bal, err := api.ModifyBalanceContext(ctx, fs.Arg(0), fs.Arg(1), update)
+	if err != nil {
+		return err
+	}
+	return c.printBalance(bal)
+}
+
+func (c *cli) printBalance(bal v1.Balance) error {
+	return c.print(bal, func(w io.Writer) {
+		row(w, "USER", "CASH", "BANK", "TOTAL", "RANK")
+		rank := "-"
+		if bal.Rank > 0 {
+			rank = strconv.Itoa(bal.Rank)
+		}
+		row(w, bal.UserID, bal.Cash, bal.Bank, bal.Total, rank)
+	})
+}
+
+func (c *cli) leaderboard(ctx context.Context, args []string) error {
+	fs := c.flags("leaderboard", "[flags] <guild>")
+	var opts v1.LeaderboardOptions
+	fs.StringVar(&opts.Sort, "sort", "total", "sort by total, cash or bank")
+	fs.IntVar(&opts.Limit, "limit", 0, "entries per page (default the API's)")
+	fs.IntVar(&opts.Offset, "offset", 0, "entries to skip")
+	fs.IntVar(&opts.Page, "page", 0, "page to show, from 1")
+	if err := c.parse(fs, args, 1); err != nil {
+		return err
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+	page, err := api.GetLeaderboardPageContext(ctx, fs.Arg(0), opts)
+	if err != nil {
+		return err
+	}
+	entries := page.Entries
+	if entries == nil {
+		entries = []v1.LeaderboardEntry{}
+	}
+	return c.print(entries, func(w io.Writer) {
+		row(w, "RANK", "USER", "CASH", "BANK", "TOTAL")
+		for _, e := range entries {
+			row(w, e.Rank, e.UserID, e.Cash, e.Bank, e.Total)
+		}
+	})
+}
+
+func (c *cli) export(ctx context.Context, args []string) error {
+	fs := c.flags("export", "[flags] <guild>")
+	format := fs.String("format", "csv", "output format: csv, json or ndjson")
+	sort := fs.String("sort", "total", "sort by total, cash or bank")
+	output := fs.String("o", "", "file to write to (default stdout)")
+	if err := c.parse(fs, args, 1); err != nil {
+		return err
+	}
+	if c.json && !isFlagSet(fs, "format") {
+		*format = string(v1.ExportJSON)
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+	opts := v1.ExportOptions{Format: v1.ExportFormat(*format), Sort: *sort}
+	if *output == "" {
+		_, err = v1.ExportLeaderboard(ctx, api, fs.Arg(0), c.stdout, opts)
+		return err
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	n, err := v1.ExportLeaderboard(ctx, api, fs.Arg(0), f, opts)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stderr, "exported %d entries to %s\n", n, *output)
+	return nil
+}
+
+// isFlagSet reports whether the flag name was given on the command line.
+func isFlagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+func (c *cli) items(ctx context.Context, args []string) error {
+	return c.subcommand(ctx, "items", args, map[string]func(c *cli, ctx context.Context, args []string) error{
+		"list": (*cli).itemsList,
+		"get":  (*cli).itemsGet,
+	})
+}
+
+func (c *cli) itemsList(ctx context.Context, args []string) error {
+	fs := c.flags("items list", "[flags] <guild>")
+	var opts v1.ListOptions
+	fs.IntVar(&opts.Limit, "limit", 0, "items per page (default the API's)")
+	fs.IntVar(&opts.Page, "page", 0, "page to show, from 1")
+	if err := c.parse(fs, args, 1); err != nil {
+		return err
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+	page, err := api.ListItemsContext(ctx, fs.Arg(0), opts)
+	if err != nil {
+		return err
+	}
+	return c.printItems(page.Items)
+}
+
+func (c *cli) itemsGet(ctx context.Context, args []string) error {
+	fs := c.flags("items get", "<guild> <item>")
+	if err := c.parse(fs, args, 2); err != nil {
+		return err
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+	item, err := api.GetItemContext(ctx, fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.print(item, nil)
+	}
+	return c.printItems([]v1.Item{item})
+}
+
+func (c *cli) printItems(items []v1.Item) error {
+	if items == nil {
+		items = []v1.Item{}
+	}
+	return c.print(items, func(w io.Writer) {
+		row(w, "ID", "NAME", "PRICE", "STOCK", "INVENTORY")
+		for _, item := range items {
+			row(w, item.ID, item.Name, item.Price, stock(item.Stock), item.Inventory)
+		}
+	})
+}
+
+func (c *cli) inventory(ctx context.Context, args []string) error {
+	return c.subcommand(ctx, "inventory", args, map[string]func(c *cli, ctx context.Context, args []string) error{
+		"list":   (*cli).inventoryList,
+		"add":    func(c *cli, ctx context.Context, args []string) error { return c.inventoryChange(ctx, "add", args) },
+		"remove": func(c *cli, ctx context.Context, args []string) error { return c.inventoryChange(ctx, "remove", args) },
+	})
+}
+
+func (c *cli) inventoryList(ctx context.Context, args []string) error {
+	fs := c.flags("inventory list", "[flags] <guild> <user>")
+	var opts v1.ListOptions
+	fs.IntVar(&opts.Limit, "limit", 0, "items per page (default the API's)")
+	fs.IntVar(&opts.Page, "page", 0, "page to show, from 1")
+	if err := c.parse(fs, args, 2); err != nil {
+		return err
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+	page, err := api.ListInventoryContext(ctx, fs.Arg(0), fs.Arg(1), opts)
+	if err != nil {
+		return err
+	}
+	return c.printInventory(page.Items)
+}
+
+// inventoryChange runs "inventory add" or "inventory remove".
+func (c *cli) inventoryChange(ctx context.Context, mode string, args []string) error {
+	fs := c.flags("inventory "+mode, "<guild> <user> <item> <quantity>")
+	if err := c.parse(fs, args, 4); err != nil {
+		return err
+	}
+	quantity, err := strconv.Atoi(fs.Arg(3))
+	if err != nil || quantity < 1 {
+		fmt.Fprintf(c.stderr, "unb: invalid quantity %q\n", fs.Arg(3))
+		fs.Usage()
+		return errUsage
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+	change := api.AddInventoryItemContext
+	if mode == "remove" {
+		change = api.RemoveInventoryItemContext
+	}
+	item, err := change(ctx, fs.Arg(0), fs.Arg(1), fs.Arg(2), quantity)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.print(item, nil)
+	}
+	return c.printInventory([]v1.InventoryItem{item})
+}
+
+func (c *cli) printInventory(items []v1.InventoryItem) error {
+	if items == nil {
+		items = []v1.InventoryItem{}
+	}
+	return c.print(items, func(w io.Writer) {
+		row(w, "ITEM", "NAME", "QUANTITY")
+		for _, item := range items {
+			row(w, item.ItemID, item.Name, item.Quantity)
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// config is the contents of the config file.
+type config struct {
+	Token string `json:"token"`
+}
+
+// resolveToken returns the token from the -token flag, $UNB_TOKEN or the
+// config file, in that order.
+func (c *cli) resolveToken() (string, error) {
+	if c.token != "" {
+		return c.token, nil
+	}
+	if token := os.Getenv("UNB_TOKEN"); token != "" {
+		return token, nil
+	}
+	path, explicit, err := c.configFile()
+	if err != nil {
+		return "", err
+	}
+	cfg, err := loadConfig(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist) && !explicit:
+		return "", errors.New("no token: set UNB_TOKEN, pass -token or add it to " + path)
+	case err != nil:
+		return "", err
+	case cfg.Token == "":
+		return "", fmt.Errorf("no token in %s", path)
+	}
+	return cfg.Token, nil
+}
+
+// configFile returns the path of the config file, and whether it was chosen
+// by the user rather than being the default.
+func (c *cli) configFile() (string, bool, error) {
+	if c.configPath != "" {
+		return c.configPath, true, nil
+	}
+	if path := os.Getenv("UNB_CONFIG"); path != "" {
+		return path, true, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", false, fmt.Errorf("no token: set UNB_TOKEN or pass -token (%v)", err)
+	}
+	return filepath.Join(dir, "unb", "config.json"), false, nil
+}
+
+func loadConfig(path string) (config, error) {
+	var cfg config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("reading %s: %v", path, err)
+	}
+	return cfg, nil
+}
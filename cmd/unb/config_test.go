@@ -0,0 +1,56 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveTokenPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"token":"from-file"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("UNB_CONFIG", path)
+
+	t.Setenv("UNB_TOKEN", "")
+	token, err := (&cli{}).resolveToken()
+	equals(t, nil, err)
+	equals(t, "from-file", token)
+
+	t.Setenv("UNB_TOKEN", "from-env")
+	token, err = (&cli{}).resolveToken()
+	equals(t, nil, err)
+	equals(t, "from-env", token)
+
+	token, err = (&cli{token: "from-flag"}).resolveToken()
+	equals(t, nil, err)
+	equals(t, "from-flag", token)
+}
+
+func TestResolveTokenErrors(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("UNB_TOKEN", "")
+	t.Setenv("UNB_CONFIG", "")
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	_, err := (&cli{}).resolveToken()
+	if err == nil || !strings.Contains(err.Error(), "UNB_TOKEN") {
+		t.Fatalf("expected a hint to set UNB_TOKEN, got %v", err)
+	}
+
+	_, err = (&cli{configPath: filepath.Join(dir, "missing.json")}).resolveToken()
+	if !os.IsNotExist(err) {
+		t.Fatalf("expected a missing explicit config to be an error, got %v", err)
+	}
+
+	empty := filepath.Join(dir, "empty.json")
+	os.WriteFile(empty, []byte(`{}`), 0600)
+	_, err = (&cli{configPath: empty}).resolveToken()
+	if err == nil || !strings.Contains(err.Error(), "no token in") {
+		t.Fatalf("expected no token error, got %v", err)
+	}
+}
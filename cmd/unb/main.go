@@ -0,0 +1,161 @@
+// Command unb is a command-line client for the UnbelievaBoat API, for fixing
+// balances and inspecting guilds without writing code:
+//
+//	unb check
+//	unb balance get <guild> <user>
+//	unb balance set [-cash n] [-bank n] [-reason text] <guild> <user>
+//	unb balance add [-cash n] [-bank n] [-reason text] [-key key] <guild> <user>
+//	unb leaderboard [-sort total|cash|bank] [-limit n] [-page n] <guild>
+//	unb export [-format csv|json|ndjson] [-sort total|cash|bank] [-o file] <guild>
+//	unb items list [-limit n] [-page n] <guild>
+//	unb items get <guild> <item>
+//	unb inventory list [-limit n] [-page n] <guild> <user>
+//	unb inventory add|remove <guild> <user> <item> <quantity>
+//
+// The token is read from the -token flag, the UNB_TOKEN environment variable
+// or the config file, in that order. The config file is JSON:
+//
+//	{"token": "..."}
+//
+// and is looked for at $UNB_CONFIG, or unb/config.json in the user's config
+// directory (~/.config on Linux).
+//
+// Balance changes made with -key are remembered for a week in
+// idempotency.json beside the config file, so that rerunning the command
+// with the same key doesn't apply the change again.
+//
+// Results are printed as tables, or as JSON with -json.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	v1 "github.com/BaileyJM02/unb-api-go/v1"
+)
+
+const usage = `usage: unb [flags] <command> [arguments]
+
+commands:
+  check                                    check the API is up
+  balance get <guild> <user>               show a user's balance
+  balance set <guild> <user>               set a user's cash and/or bank
+  balance add <guild> <user>               add to a user's cash and/or bank
+  leaderboard <guild>                      show the guild leaderboard
+  export <guild>                           export the whole leaderboard as CSV, JSON or NDJSON
+  items list <guild>                       list the guild's store items
+  items get <guild> <item>                 show a store item
+  inventory list <guild> <user>            list a user's inventory
+  inventory add <guild> <user> <item> <n>  give a user n of an item
+  inventory remove <guild> <user> <item> <n>
+                                           take n of an item from a user
+
+Run "unb <command> -h" for a command's flags.
+
+flags:
+`
+
+// errUsage is returned for bad command lines, after the usage has been
+// printed.
+var errUsage = errors.New("usage")
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run runs the command line args and returns the exit status: 0 on success,
+// 1 if the command failed and 2 if it was used wrongly.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	c := &cli{stdout: stdout, stderr: stderr}
+	err := c.run(ctx, args)
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, errUsage):
+		return 2
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	}
+	fmt.Fprintln(stderr, "unb:", err)
+	return 1
+}
+
+// cli holds the global flags and the client commands use.
+type cli struct {
+	stdout, stderr io.Writer
+
+	token      string
+	configPath string
+	baseURL    string
+	timeout    time.Duration
+	json       bool
+
+	api v1.API
+}
+
+func (c *cli) run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("unb", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprint(c.stderr, usage)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&c.token, "token", "", "API token (default $UNB_TOKEN or the config file)")
+	fs.StringVar(&c.configPath, "config", "", "config file (default $UNB_CONFIG or unb/config.json in the user config directory)")
+	fs.StringVar(&c.baseURL, "base-url", v1.DefaultBaseURL, "API root")
+	fs.DurationVar(&c.timeout, "timeout", 10*time.Second, "timeout for each command's API call, including retries and rate limit waits")
+	c.jsonFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	name, args := fs.Arg(0), fs.Args()[1:]
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(c.stderr, "unb: unknown command %q\n", name)
+		fs.Usage()
+		return errUsage
+	}
+	return cmd(c, ctx, args)
+}
+
+// jsonFlag adds the -json flag to fs, so that it can be given before or
+// after the command.
+func (c *cli) jsonFlag(fs *flag.FlagSet) {
+	fs.BoolVar(&c.json, "json", c.json, "print results as JSON")
+}
+
+// client returns the API client, creating it with opts on first use.
+func (c *cli) client(opts ...v1.Option) (v1.API, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	token, err := c.resolveToken()
+	if err != nil {
+		return nil, err
+	}
+	api, err := v1.NewClient(token, append([]v1.Option{
+		v1.WithBaseURL(c.baseURL),
+		v1.WithTimeout(c.timeout),
+		v1.WithRetryPolicy(v1.DefaultRetryPolicy()),
+		v1.WithUserAgent("unb-cli (+https://github.com/BaileyJM02/unb-api-go)"),
+	}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	c.api = api
+	return api, nil
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	v1 "github.com/BaileyJM02/unb-api-go/v1"
+	"github.com/BaileyJM02/unb-api-go/v1/unbtest"
+)
+
+const (
+	guildID = "411898639737421824"
+	userID  = "398197113495748626"
+)
+
+// unb runs the command line args against srv, returning the exit status and
+// what was printed.
+func unb(t *testing.T, srv *unbtest.Server, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	args = append([]string{"-token", srv.Token, "-base-url", srv.URL}, args...)
+	code := run(context.Background(), args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func equals(t *testing.T, exp, act interface{}) {
+	t.Helper()
+	if !reflect.DeepEqual(exp, act) {
+		t.Fatalf("exp: %#v\n\ngot: %#v", exp, act)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	srv := unbtest.NewServer("token")
+	defer srv.Close()
+
+	code, out, _ := unb(t, srv, "check")
+	equals(t, 0, code)
+	assertContains(t, out, "up")
+}
+
+func TestBalanceGetPrintsTable(t *testing.T) {
+	srv := unbtest.NewServer("token")
+	defer srv.Close()
+	srv.SetBalance(guildID, userID, v1.NewAmount(1500), v1.NewAmount(20))
+
+	code, out, stderr := unb(t, srv, "balance", "get", guildID, userID)
+	equals(t, 0, code)
+	equals(t, "", stderr)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	equals(t, 2, len(lines))
+	equals(t, []string{"USER", "CASH", "BANK", "TOTAL", "RANK"}, strings.Fields(lines[0]))
+	equals(t, []string{userID, "1500", "20", "1520"}, strings.Fields(lines[1])[:4])
+}
+
+func TestBalanceGetPrintsJSON(t *testing.T) {
+	srv := unbtest.NewServer("token")
+	defer srv.Close()
+	srv.SetBalance(guildID, userID, v1.NewAmount(1500), v1.NewAmount(20))
+
+	for _, args := range [][]string{
+		{"-json", "balance", "get", guildID, userID},
+		{"balance", "get", "-json", guildID, userID},
+	} {
+		code, out, _ := unb(t, srv, args...)
+		equals(t, 0, code)
+		var bal v1.Balance
+		if err := json.Unmarshal([]byte(out), &bal); err != nil {
+			t.Fatalf("%v: %q", err, out)
+		}
+		equals(t, "1520", bal.Total.String())
+	}
+}
+
+func TestBalanceSetAndAdd(t *testing.T) {
+	srv := unbtest.NewServer("token")
+	defer srv.Close()
+	srv.SetBalance(guildID, userID, v1.NewAmount(100), v1.NewAmount(0))
+
+	code, _, stderr := unb(t, srv, "balance", "add", "-cash", "-30", "-bank", "5", "-reason", "Refund", guildID, userID)
+	equals(t, 0, code)
+	equals(t, "", stderr)
+	bal, _ := srv.Balance(guildID, userID)
+	equals(t, "70", bal.Cash.String())
+	equals(t, "5", bal.Bank.String())
+
+	code, _, _ = unb(t, srv, "balance", "set", "-bank", "Infinity", guildID, userID)
+	equals(t, 0, code)
+	bal, _ = srv.Balance(guildID, userID)
+	equals(t, "70", bal.Cash.String())
+	equals(t, true, bal.Bank.IsInf(1))
+}
+
+func TestBalanceSetNeedsAnAmount(t *testing.T) {
+	srv := unbtest.NewServer("token")
+	defer srv.Close()
+
+	code, _, stderr := unb(t, srv, "balance", "set", guildID, userID)
+	equals(t, 2, code)
+	assertContains(t, stderr, "give -cash and/or -bank")
+	equals(t, 0, len(srv.Requests()))
+}
+
+func TestLeaderboard(t *testing.T) {
+	srv := unbtest.NewServer("token")
+	defer srv.Close()
+	srv.SetBalance(guildID, "1", v1.NewAmount(10), v1.NewAmount(0))
+	srv.SetBalance(guildID, "2", v1.NewAmount(30), v1.NewAmount(0))
+	srv.SetBalance(guildID, "3", v1.NewAmount(20), v1.NewAmount(0))
+
+	code, out, _ := unb(t, srv, "leaderboard", "-limit", "2", guildID)
+	equals(t, 0, code)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	equals(t, 3, len(lines))
+	equals(t, []string{"1", "2", "30", "0", "30"}, strings.Fields(lines[1]))
+	equals(t, []string{"2", "3", "20", "0", "20"}, strings.Fields(lines[2]))
+}
+
+func TestItemsAndInventory(t *testing.T) {
+	srv := unbtest.NewServer("token")
+	defer srv.Close()
+	item := srv.AddItem(guildID, v1.Item{Name: "Pizza", Price: v1.NewAmount(25), Inventory: true})
+
+	code, out, _ := unb(t, srv, "items", "list", guildID)
+	equals(t, 0, code)
+	assertContains(t, out, "Pizza")
+	assertContains(t, out, "unlimited")
+
+	code, _, stderr := unb(t, srv, "inventory", "add", guildID, userID, item.ID, "3")
+	equals(t, 0, code)
+	equals(t, "", stderr)
+	code, _, _ = unb(t, srv, "inventory", "remove", guildID, userID, item.ID, "1")
+	equals(t, 0, code)
+	equals(t, 2, srv.Inventory(guildID, userID, item.ID))
+
+	code, out, _ = unb(t, srv, "-json", "inventory", "list", guildID, userID)
+	equals(t, 0, code)
+	var items []v1.InventoryItem
+	if err := json.Unmarshal([]byte(out), &items); err != nil {
+		t.Fatalf("%v: %q", err, out)
+	}
+	equals(t, 1, len(items))
+	equals(t, 2, items[0].Quantity)
+}
+
+func TestAPIErrorsExitNonZero(t *testing.T) {
+	srv := unbtest.NewServer("token")
+	defer srv.Close()
+	srv.FailNext(403, "Missing Permissions")
+
+	code, out, stderr := unb(t, srv, "balance", "get", guildID, userID)
+	equals(t, 1, code)
+	equals(t, "", out)
+	assertContains(t, stderr, "Missing Permissions")
+}
+
+func TestUsageErrors(t *testing.T) {
+	srv := unbtest.NewServer("token")
+	defer srv.Close()
+
+	for _, args := range [][]string{
+		{},
+		{"bogus"},
+		{"balance"},
+		{"balance", "get", guildID},
+		{"inventory", "add", guildID, userID, "1", "none"},
+	} {
+		code, _, stderr := unb(t, srv, args...)
+		if code != 2 {
+			t.Errorf("%q: expected exit status 2, got %d", args, code)
+		}
+		assertContains(t, stderr, "usage: unb")
+	}
+}
+
+func assertContains(t *testing.T, s, substr string) {
+	t.Helper()
+	if !strings.Contains(s, substr) {
+		t.Fatalf("expected %q in %q", substr, s)
+	}
+}
+
+func TestExport(t *testing.T) {
+	srv := unbtest.NewServer("token")
+	defer srv.Close()
+	srv.SetBalance(guildID, "1", v1.NewAmount(10), v1.NewAmount(0))
+	srv.SetBalance(guildID, "2", v1.Inf(1), v1.NewAmount(0))
+
+	code, out, _ := unb(t, srv, "export", guildID)
+	equals(t, 0, code)
+	equals(t, "rank,user_id,cash,bank,total,cash_infinite,bank_infinite,total_infinite\n"+
+		"1,2,Infinity,0,Infinity,true,false,true\n"+
+		"2,1,10,0,10,false,false,false\n", out)
+
+	code, out, _ = unb(t, srv, "-json", "export", guildID)
+	equals(t, 0, code)
+	var rows []map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &rows); err != nil {
+		t.Fatalf("%v: %q", err, out)
+	}
+	equals(t, 2, len(rows))
+
+	path := filepath.Join(t.TempDir(), "economy.ndjson")
+	code, out, stderr := unb(t, srv, "export", "-format", "ndjson", "-o", path, guildID)
+	equals(t, 0, code)
+	equals(t, "", out)
+	assertContains(t, stderr, "exported 2 entries")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	equals(t, 2, strings.Count(string(data), "\n"))
+}
+
+func TestBalanceAddKeyAppliesOnceAcrossRuns(t *testing.T) {
+	srv := unbtest.NewServer("token")
+	defer srv.Close()
+	srv.SetBalance(guildID, userID, v1.NewAmount(100), v1.NewAmount(0))
+	dir := t.TempDir()
+	t.Setenv("UNB_CONFIG", filepath.Join(dir, "config.json"))
+
+	for i := 0; i < 2; i++ {
+		code, out, stderr := unb(t, srv, "balance", "add", "-cash", "50", "-key", "k", guildID, userID)
+		equals(t, 0, code)
+		equals(t, "", stderr)
+		assertContains(t, out, "150")
+	}
+	bal, _ := srv.Balance(guildID, userID)
+	equals(t, "150", bal.Cash.String())
+	if _, err := os.Stat(filepath.Join(dir, "idempotency.json")); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	v1 "github.com/BaileyJM02/unb-api-go/v1"
+)
+
+// idempotencyTTL is how long -key records are kept.
+const idempotencyTTL = 7 * 24 * time.Hour
+
+// fileStore is a v1.IdempotencyStore kept in a JSON file, so that -key holds
+// across runs of the command. Every operation holds a lock file while it
+// reads and rewrites the records, which makes CompareAndSwap atomic across
+// runs on the same machine.
+type fileStore struct {
+	path string
+}
+
+var _ v1.IdempotencyStore = (*fileStore)(nil)
+
+// idempotencyStore returns the store kept next to the config file.
+func (c *cli) idempotencyStore() (*fileStore, error) {
+	path, _, err := c.configFile()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &fileStore{path: filepath.Join(dir, "idempotency.json")}, nil
+}
+
+// Get implements v1.IdempotencyStore.
+func (s *fileStore) Get(ctx context.Context, key string) (rec v1.IdempotencyRecord, found bool, err error) {
+	err = s.update(ctx, func(recs map[string]v1.IdempotencyRecord) bool {
+		rec, found = recs[key]
+		return false
+	})
+	return rec, found, err
+}
+
+// CompareAndSwap implements v1.IdempotencyStore.
+func (s *fileStore) CompareAndSwap(ctx context.Context, key string, old int, rec v1.IdempotencyRecord) (swapped bool, err error) {
+	err = s.update(ctx, func(recs map[string]v1.IdempotencyRecord) bool {
+		if recs[key].Version != old {
+			return false
+		}
+		recs[key], swapped = rec, true
+		return true
+	})
+	return swapped, err
+}
+
+// Delete implements v1.IdempotencyStore.
+func (s *fileStore) Delete(ctx context.Context, key string) error {
+	return s.update(ctx, func(recs map[string]v1.IdempotencyRecord) bool {
+		_, ok := recs[key]
+		delete(recs, key)
+		return ok
+	})
+}
+
+// update calls fn with the unexpired records while holding the lock, and
+// saves them if fn reports a change.
+func (s *fileStore) update(ctx context.Context, fn func(recs map[string]v1.IdempotencyRecord) bool) error {
+	unlock, err := s.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	recs := make(map[string]v1.IdempotencyRecord)
+	data, err := os.ReadFile(s.path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return err
+	default:
+		if err := json.Unmarshal(data, &recs); err != nil {
+			return fmt.Errorf("reading %s: %v", s.path, err)
+		}
+	}
+	changed := false
+	for k, rec := range recs {
+		if time.Since(rec.CreatedAt) > idempotencyTTL {
+			delete(recs, k)
+			changed = true
+		}
+	}
+	if !fn(recs) && !changed {
+		return nil
+	}
+
+	data, err = json.MarshalIndent(recs, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// lock takes the lock file, waiting while another run holds it. A lock
+// older than a minute is left over from a run that crashed, and is broken.
+func (s *fileStore) lock(ctx context.Context) (unlock func(), err error) {
+	path := s.path + ".lock"
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > time.Minute {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%s is locked by another run of unb", s.path)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	v1 "github.com/BaileyJM02/unb-api-go/v1"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	s := &fileStore{path: filepath.Join(t.TempDir(), "idempotency.json")}
+
+	swapped, err := s.CompareAndSwap(ctx, "k", 0, v1.IdempotencyRecord{Version: 1, CreatedAt: time.Now()})
+	equals(t, nil, err)
+	equals(t, true, swapped)
+	swapped, err = s.CompareAndSwap(ctx, "k", 0, v1.IdempotencyRecord{Version: 1, CreatedAt: time.Now()})
+	equals(t, nil, err)
+	equals(t, false, swapped)
+
+	// Records persist in the file, and expired ones are dropped.
+	s = &fileStore{path: s.path}
+	_, err = s.CompareAndSwap(ctx, "old", 0, v1.IdempotencyRecord{Version: 1, CreatedAt: time.Now().Add(-30 * 24 * time.Hour)})
+	equals(t, nil, err)
+	rec, found, err := s.Get(ctx, "k")
+	equals(t, nil, err)
+	equals(t, true, found)
+	equals(t, 1, rec.Version)
+	_, found, _ = s.Get(ctx, "old")
+	equals(t, false, found)
+
+	equals(t, nil, s.Delete(ctx, "k"))
+	_, found, _ = s.Get(ctx, "k")
+	equals(t, false, found)
+}
+
+func TestFileStoreBreaksStaleLock(t *testing.T) {
+	s := &fileStore{path: filepath.Join(t.TempDir(), "idempotency.json")}
+	lock := s.path + ".lock"
+	if err := os.WriteFile(lock, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(lock, old, old)
+
+	_, _, err := s.Get(context.Background(), "k")
+	equals(t, nil, err)
+	if _, err := os.Stat(lock); !os.IsNotExist(err) {
+		t.Fatalf("expected the lock to be released, got %v", err)
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/BaileyJM02/unb-api-go
+
+go 1.23
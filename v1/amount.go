@@ -0,0 +1,218 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Amount is an amount of currency: a whole number of any size, or positive
+// or negative infinity. The zero value is 0.
+//
+// Amounts are immutable; arithmetic returns a new Amount. They encode to JSON
+// the way the API does, as a number or as the string "Infinity" or
+// "-Infinity", and decode from numbers and numeric strings alike.
+type Amount struct {
+	n   *big.Int // nil means 0; never modified once set
+	inf int8     // +1 or -1 when infinite
+}
+
+// NewAmount returns the finite amount n.
+func NewAmount(n int64) Amount {
+	return NewAmountFromBig(big.NewInt(n))
+}
+
+// NewAmountFromBig returns the finite amount n. n is copied.
+func NewAmountFromBig(n *big.Int) Amount {
+	if n == nil || n.Sign() == 0 {
+		return Amount{}
+	}
+	return Amount{n: new(big.Int).Set(n)}
+}
+
+// Inf returns positive infinity if sign >= 0, negative infinity if sign < 0.
+func Inf(sign int) Amount {
+	if sign < 0 {
+		return Amount{inf: -1}
+	}
+	return Amount{inf: 1}
+}
+
+// ParseAmount parses a whole number in base 10, "Infinity" or "-Infinity".
+func ParseAmount(s string) (Amount, error) {
+	switch s {
+	case "Infinity", "+Infinity":
+		return Inf(1), nil
+	case "-Infinity":
+		return Inf(-1), nil
+	}
+	if n, ok := new(big.Int).SetString(s, 10); ok {
+		return NewAmountFromBig(n), nil
+	}
+	// Large balances may come back in exponent form, e.g. 1e+21.
+	if f, _, err := big.ParseFloat(s, 10, 256, big.ToNearestEven); err == nil && f.IsInt() {
+		n, _ := f.Int(nil)
+		return NewAmountFromBig(n), nil
+	}
+	return Amount{}, fmt.Errorf("v1: invalid amount %q", s)
+}
+
+// IsInf reports whether a is infinite, like math.IsInf: with sign > 0 only
+// positive infinity matches, with sign < 0 only negative infinity, and with
+// sign == 0 either.
+func (a Amount) IsInf(sign int) bool {
+	return a.inf != 0 && (sign == 0 || (sign > 0) == (a.inf > 0))
+}
+
+// Sign returns -1, 0 or +1 depending on whether a is negative, zero or
+// positive.
+func (a Amount) Sign() int {
+	if a.inf != 0 {
+		return int(a.inf)
+	}
+	if a.n == nil {
+		return 0
+	}
+	return a.n.Sign()
+}
+
+// Big returns a as a new big.Int, or nil if a is infinite.
+func (a Amount) Big() *big.Int {
+	if a.inf != 0 {
+		return nil
+	}
+	if a.n == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(a.n)
+}
+
+// Int64 returns a as an int64, and whether it fits in one. Infinite amounts
+// never fit.
+func (a Amount) Int64() (int64, bool) {
+	if a.inf != 0 {
+		return 0, false
+	}
+	if a.n == nil {
+		return 0, true
+	}
+	return a.n.Int64(), a.n.IsInt64()
+}
+
+// Add returns a+b. Infinity plus a finite amount is infinity, and, as the
+// API computes totals, infinity plus negative infinity is 0.
+func (a Amount) Add(b Amount) Amount {
+	switch {
+	case a.inf != 0 && b.inf != 0:
+		if a.inf == b.inf {
+			return a
+		}
+		return Amount{}
+	case a.inf != 0:
+		return a
+	case b.inf != 0:
+		return b
+	}
+	return NewAmountFromBig(new(big.Int).Add(a.Big(), b.Big()))
+}
+
+// Sub returns a-b.
+func (a Amount) Sub(b Amount) Amount {
+	return a.Add(b.Neg())
+}
+
+// Neg returns -a.
+func (a Amount) Neg() Amount {
+	if a.inf != 0 {
+		return Amount{inf: -a.inf}
+	}
+	if a.n == nil {
+		return a
+	}
+	return Amount{n: new(big.Int).Neg(a.n)}
+}
+
+// Cmp compares a and b and returns -1 if a < b, 0 if a == b and +1 if a > b.
+// Infinities compare equal to themselves.
+func (a Amount) Cmp(b Amount) int {
+	if a.inf != 0 || b.inf != 0 {
+		switch {
+		case a.inf == b.inf:
+			return 0
+		case a.inf > b.inf:
+			return 1
+		}
+		return -1
+	}
+	return a.Big().Cmp(b.Big())
+}
+
+// String returns a in base 10, or "Infinity" or "-Infinity".
+func (a Amount) String() string {
+	switch {
+	case a.inf > 0:
+		return "Infinity"
+	case a.inf < 0:
+		return "-Infinity"
+	case a.n == nil:
+		return "0"
+	}
+	return a.n.String()
+}
+
+// FormatCurrency returns a for display with the guild's currency symbol and
+// thousands separators, e.g. "-£1,234" or "£∞".
+func (a Amount) FormatCurrency(symbol string) string {
+	sign := ""
+	if a.Sign() < 0 {
+		sign = "-"
+	}
+	if a.inf != 0 {
+		return sign + symbol + "∞"
+	}
+	digits := strings.TrimPrefix(a.String(), "-")
+	var b strings.Builder
+	b.WriteString(sign)
+	b.WriteString(symbol)
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	return b.String()
+}
+
+// MarshalJSON encodes a as a JSON number, or as "Infinity" or "-Infinity".
+func (a Amount) MarshalJSON() ([]byte, error) {
+	if a.inf != 0 {
+		return json.Marshal(a.String())
+	}
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON decodes a JSON number, a string holding a number,
+// "Infinity", "-Infinity" or null (as 0).
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		*a = Amount{}
+		return nil
+	}
+	s := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		var err error
+		if s, err = strconv.Unquote(s); err != nil {
+			return fmt.Errorf("v1: invalid amount %s", data)
+		}
+	}
+	v, err := ParseAmount(s)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
@@ -0,0 +1,111 @@
+package v1
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func mustParseAmount(tb testing.TB, s string) Amount {
+	a, err := ParseAmount(s)
+	ok(tb, err)
+	return a
+}
+
+func TestParseAmount(t *testing.T) {
+	equals(t, NewAmount(25), mustParseAmount(t, "25"))
+	equals(t, NewAmount(-40), mustParseAmount(t, "-40"))
+	equals(t, Amount{}, mustParseAmount(t, "0"))
+	equals(t, Inf(1), mustParseAmount(t, "Infinity"))
+	equals(t, Inf(-1), mustParseAmount(t, "-Infinity"))
+	equals(t, "1000000000000000000000", mustParseAmount(t, "1e+21").String())
+	for _, s := range []string{"", "abc", "1.5", "NaN"} {
+		_, err := ParseAmount(s)
+		assert(t, err != nil, "expected an error for %q", s)
+	}
+}
+
+func TestAmountUnmarshalsNumbersStringsAndInfinity(t *testing.T) {
+	var v struct {
+		A, B, C, D, E, F Amount
+	}
+	ok(t, json.Unmarshal([]byte(`{"A":25,"B":"-33","C":"Infinity","D":"-Infinity","E":null,"F":123456789012345678901234567890}`), &v))
+	equals(t, NewAmount(25), v.A)
+	equals(t, NewAmount(-33), v.B)
+	equals(t, Inf(1), v.C)
+	equals(t, Inf(-1), v.D)
+	equals(t, Amount{}, v.E)
+	equals(t, "123456789012345678901234567890", v.F.String())
+
+	err := json.Unmarshal([]byte(`{"A":"lots"}`), &v)
+	assert(t, err != nil, "expected an error for a non-numeric amount")
+}
+
+func TestAmountMarshalsLikeTheAPI(t *testing.T) {
+	b, err := json.Marshal([]Amount{NewAmount(25), {}, Inf(1), Inf(-1), mustParseAmount(t, "123456789012345678901234567890")})
+	ok(t, err)
+	equals(t, `[25,0,"Infinity","-Infinity",123456789012345678901234567890]`, string(b))
+}
+
+func TestAmountArithmetic(t *testing.T) {
+	huge := mustParseAmount(t, "9223372036854775807")
+	equals(t, "9223372036854775808", huge.Add(NewAmount(1)).String())
+	equals(t, NewAmount(-15), NewAmount(10).Sub(NewAmount(25)))
+	equals(t, Amount{}, NewAmount(10).Sub(NewAmount(10)))
+	equals(t, Inf(1), Inf(1).Add(NewAmount(-1000)))
+	equals(t, Inf(-1), NewAmount(5).Sub(Inf(1)))
+	// The API reports a total of 0 for infinite cash and negative infinite bank.
+	equals(t, Amount{}, Inf(1).Add(Inf(-1)))
+	equals(t, Inf(-1), Inf(1).Neg())
+}
+
+func TestAmountComparison(t *testing.T) {
+	equals(t, -1, NewAmount(1).Cmp(NewAmount(2)))
+	equals(t, 0, NewAmount(2).Cmp(mustParseAmount(t, "2")))
+	equals(t, 1, Inf(1).Cmp(mustParseAmount(t, "123456789012345678901234567890")))
+	equals(t, -1, Inf(-1).Cmp(NewAmount(-5)))
+	equals(t, 0, Inf(1).Cmp(Inf(1)))
+	equals(t, -1, Amount{}.Sign()+Inf(-1).Sign())
+	assert(t, Inf(1).IsInf(0) && Inf(1).IsInf(1) && !Inf(1).IsInf(-1), "IsInf mismatch for +Inf")
+	assert(t, !NewAmount(1).IsInf(0), "finite amount reported as infinite")
+}
+
+func TestAmountConversions(t *testing.T) {
+	n, fits := NewAmount(-42).Int64()
+	equals(t, int64(-42), n)
+	equals(t, true, fits)
+	_, fits = mustParseAmount(t, "123456789012345678901234567890").Int64()
+	equals(t, false, fits)
+	_, fits = Inf(1).Int64()
+	equals(t, false, fits)
+	equals(t, big.NewInt(7), NewAmount(7).Big())
+	equals(t, (*big.Int)(nil), Inf(1).Big())
+}
+
+func TestAmountFormatCurrency(t *testing.T) {
+	equals(t, "£0", Amount{}.FormatCurrency("£"))
+	equals(t, "£999", NewAmount(999).FormatCurrency("£"))
+	equals(t, "£1,000", NewAmount(1000).FormatCurrency("£"))
+	equals(t, "-£1,234,567", NewAmount(-1234567).FormatCurrency("£"))
+	equals(t, "💰∞", Inf(1).FormatCurrency("💰"))
+	equals(t, "-$∞", Inf(-1).FormatCurrency("$"))
+}
+
+func TestGetBalanceDecodesBalancesBeyondInt64(t *testing.T) {
+	client := setClient(200, "/guilds/411898639737421824/users/398197113495748626", `{"rank":1,"user_id":"398197113495748626","cash":"99999999999999999999","bank":1,"total":"100000000000000000000"}`)
+
+	api := Custom("token", client)
+	data, err := api.GetBalance("411898639737421824", "398197113495748626")
+	ok(t, err)
+	equals(t, 1, data.Rank)
+	equals(t, "99999999999999999999", data.Cash.String())
+	equals(t, data.Total, data.Cash.Add(data.Bank))
+}
+
+func TestGetBalanceRejectsMalformedAmounts(t *testing.T) {
+	client := setClient(200, "/guilds/411898639737421824/users/398197113495748626", `{"user_id":"398197113495748626","cash":"lots","bank":1,"total":1}`)
+
+	api := Custom("token", client)
+	_, err := api.GetBalance("411898639737421824", "398197113495748626")
+	assert(t, err != nil, "expected an error for a malformed amount")
+}
@@ -0,0 +1,63 @@
+package v1
+
+import "context"
+
+// API is the set of UnbelievaBoat endpoints. It is implemented by *Client,
+// and code that accepts an API instead of a *Client can be unit-tested with
+// a fake such as unbtest.Fake:
+//
+//	func payday(api v1.API, guild, user string) error {
+//		_, err := api.UpdateBalance(guild, user, 100, 0, "Payday")
+//		return err
+//	}
+type API interface {
+	Check() (HealthStatus, error)
+	CheckContext(ctx context.Context) (HealthStatus, error)
+
+	GetBalance(guild, user string) (Balance, error)
+	GetBalanceContext(ctx context.Context, guild, user string) (Balance, error)
+	SetBalance(guild, user string, cash, bank, reason interface{}) (Balance, error)
+	SetBalanceContext(ctx context.Context, guild, user string, cash, bank, reason interface{}) (Balance, error)
+	UpdateBalance(guild, user string, cash, bank int, reason interface{}) (Balance, error)
+	UpdateBalanceContext(ctx context.Context, guild, user string, cash, bank int, reason interface{}) (Balance, error)
+	ModifyBalance(guild, user string, update *BalanceUpdate) (Balance, error)
+	ModifyBalanceContext(ctx context.Context, guild, user string, update *BalanceUpdate) (Balance, error)
+
+	Leaderboard(guild string) ([]LeaderboardEntry, error)
+	LeaderboardContext(ctx context.Context, guild string) ([]LeaderboardEntry, error)
+	GetLeaderboardPage(guild string, opts LeaderboardOptions) (LeaderboardPage, error)
+	GetLeaderboardPageContext(ctx context.Context, guild string, opts LeaderboardOptions) (LeaderboardPage, error)
+	IterateLeaderboard(guild string, opts LeaderboardOptions) *Iterator[LeaderboardEntry]
+	IterateLeaderboardContext(ctx context.Context, guild string, opts LeaderboardOptions) *Iterator[LeaderboardEntry]
+
+	GetGuild(guild string) (Guild, error)
+	GetGuildContext(ctx context.Context, guild string) (Guild, error)
+	GetPermissions(guild string) (Permissions, error)
+	GetPermissionsContext(ctx context.Context, guild string) (Permissions, error)
+
+	ListItems(guild string, opts ListOptions) (ItemPage, error)
+	ListItemsContext(ctx context.Context, guild string, opts ListOptions) (ItemPage, error)
+	IterateItems(guild string, opts ListOptions) *Iterator[Item]
+	IterateItemsContext(ctx context.Context, guild string, opts ListOptions) *Iterator[Item]
+	GetItem(guild, item string) (Item, error)
+	GetItemContext(ctx context.Context, guild, item string) (Item, error)
+	CreateItem(guild string, item Item) (Item, error)
+	CreateItemContext(ctx context.Context, guild string, item Item) (Item, error)
+	UpdateItem(guild, item string, update ItemUpdate) (Item, error)
+	UpdateItemContext(ctx context.Context, guild, item string, update ItemUpdate) (Item, error)
+	DeleteItem(guild, item string) error
+	DeleteItemContext(ctx context.Context, guild, item string) error
+
+	ListInventory(guild, user string, opts ListOptions) (InventoryPage, error)
+	ListInventoryContext(ctx context.Context, guild, user string, opts ListOptions) (InventoryPage, error)
+	IterateInventory(guild, user string, opts ListOptions) *Iterator[InventoryItem]
+	IterateInventoryContext(ctx context.Context, guild, user string, opts ListOptions) *Iterator[InventoryItem]
+	GetInventoryItem(guild, user, item string) (InventoryItem, error)
+	GetInventoryItemContext(ctx context.Context, guild, user, item string) (InventoryItem, error)
+	AddInventoryItem(guild, user, item string, quantity int) (InventoryItem, error)
+	AddInventoryItemContext(ctx context.Context, guild, user, item string, quantity int) (InventoryItem, error)
+	RemoveInventoryItem(guild, user, item string, quantity int) (InventoryItem, error)
+	RemoveInventoryItemContext(ctx context.Context, guild, user, item string, quantity int) (InventoryItem, error)
+}
+
+var _ API = (*Client)(nil)
@@ -0,0 +1,257 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+)
+
+// ErrInvalidUpdate is wrapped by every error returned for a BalanceUpdate
+// that cannot be sent.
+var ErrInvalidUpdate = errors.New("v1: invalid balance update")
+
+// BalanceUpdate describes a change to a user's balance: either setting cash
+// and/or bank to new values, or adding to them. Build one with
+// NewBalanceUpdate and pass it to ModifyBalance:
+//
+//	update := v1.NewBalanceUpdate().AddCash(v1.NewAmount(500)).Reason("Payday")
+//	bal, err := api.ModifyBalance(guildID, userID, update)
+//
+// Mistakes such as setting one field and adding to another are reported by
+// Validate, and ModifyBalance refuses to send an update that doesn't
+// validate.
+type BalanceUpdate struct {
+	cash, bank *Amount
+	add        bool
+	set        bool
+	reason     string
+	key        string
+	err        error
+}
+
+// NewBalanceUpdate returns an empty update.
+func NewBalanceUpdate() *BalanceUpdate {
+	return &BalanceUpdate{}
+}
+
+// SetCash sets the user's cash to a.
+func (u *BalanceUpdate) SetCash(a Amount) *BalanceUpdate {
+	u.set = true
+	return u.field("cash", &u.cash, a)
+}
+
+// SetBank sets the user's bank to a.
+func (u *BalanceUpdate) SetBank(a Amount) *BalanceUpdate {
+	u.set = true
+	return u.field("bank", &u.bank, a)
+}
+
+// AddCash adds a, which may be negative, to the user's cash.
+func (u *BalanceUpdate) AddCash(a Amount) *BalanceUpdate {
+	u.add = true
+	return u.field("cash", &u.cash, a)
+}
+
+// AddBank adds a, which may be negative, to the user's bank.
+func (u *BalanceUpdate) AddBank(a Amount) *BalanceUpdate {
+	u.add = true
+	return u.field("bank", &u.bank, a)
+}
+
+// InfiniteCash sets the user's cash to infinity.
+func (u *BalanceUpdate) InfiniteCash() *BalanceUpdate {
+	return u.SetCash(Inf(1))
+}
+
+// InfiniteBank sets the user's bank to infinity.
+func (u *BalanceUpdate) InfiniteBank() *BalanceUpdate {
+	return u.SetBank(Inf(1))
+}
+
+// Reason sets the reason shown in the guild's audit log.
+func (u *BalanceUpdate) Reason(reason string) *BalanceUpdate {
+	u.reason = reason
+	return u
+}
+
+// IdempotencyKey makes the update safe to retry: ModifyBalance applies an
+// update with a given key at most once, remembering it in the client's
+// IdempotencyStore. Use a key that identifies the reason for the change,
+// such as "payday:2024-05-01:<user>". While one call with a key is being
+// sent, others with the same key fail with ErrInFlight.
+func (u *BalanceUpdate) IdempotencyKey(key string) *BalanceUpdate {
+	u.key = key
+	return u
+}
+
+func (u *BalanceUpdate) field(name string, dst **Amount, a Amount) *BalanceUpdate {
+	if *dst != nil && u.err == nil {
+		u.err = fmt.Errorf("%w: %s given more than once", ErrInvalidUpdate, name)
+	}
+	*dst = &a
+	return u
+}
+
+// Validate reports why the update cannot be sent, if it can't.
+func (u *BalanceUpdate) Validate() error {
+	switch {
+	case u == nil || (u.cash == nil && u.bank == nil):
+		return fmt.Errorf("%w: nothing to update", ErrInvalidUpdate)
+	case u.err != nil:
+		return u.err
+	case u.set && u.add:
+		return fmt.Errorf("%w: cannot both set and add in one update", ErrInvalidUpdate)
+	}
+	return nil
+}
+
+// IsAdd reports whether the update adds to the balance rather than setting
+// it.
+func (u *BalanceUpdate) IsAdd() bool {
+	return u.add
+}
+
+// CashChange returns the cash amount to set or add, and whether there is one.
+func (u *BalanceUpdate) CashChange() (Amount, bool) {
+	if u.cash == nil {
+		return Amount{}, false
+	}
+	return *u.cash, true
+}
+
+// BankChange returns the bank amount to set or add, and whether there is one.
+func (u *BalanceUpdate) BankChange() (Amount, bool) {
+	if u.bank == nil {
+		return Amount{}, false
+	}
+	return *u.bank, true
+}
+
+// ReasonText returns the reason given with Reason.
+func (u *BalanceUpdate) ReasonText() string {
+	return u.reason
+}
+
+// Apply returns the balance b would have after the update.
+func (u *BalanceUpdate) Apply(b Balance) Balance {
+	if u.cash != nil {
+		if u.add {
+			b.Cash = b.Cash.Add(*u.cash)
+		} else {
+			b.Cash = *u.cash
+		}
+	}
+	if u.bank != nil {
+		if u.add {
+			b.Bank = b.Bank.Add(*u.bank)
+		} else {
+			b.Bank = *u.bank
+		}
+	}
+	b.Total = b.Cash.Add(b.Bank)
+	return b
+}
+
+func (u *BalanceUpdate) method() string {
+	if u.add {
+		return http.MethodPatch
+	}
+	return http.MethodPut
+}
+
+func (u *BalanceUpdate) payload() ([]byte, error) {
+	return json.Marshal(userObjPut{Cash: u.cash, Bank: u.bank, Reason: u.reason})
+}
+
+type userObjPut struct {
+	Cash   *Amount `json:"cash,omitempty"`
+	Bank   *Amount `json:"bank,omitempty"`
+	Reason string  `json:"reason,omitempty"`
+}
+
+// ModifyBalance applies update to a user's balance in a guild and returns
+// the new balance. Nothing is sent if update doesn't validate. If update has
+// an IdempotencyKey, it is applied at most once; see IdempotencyStore.
+func (u *Client) ModifyBalance(guild, user string, update *BalanceUpdate) (Balance, error) {
+	return u.ModifyBalanceContext(context.Background(), guild, user, update)
+}
+
+// ModifyBalanceContext is like ModifyBalance but bound to ctx.
+func (u *Client) ModifyBalanceContext(ctx context.Context, guild, user string, update *BalanceUpdate) (Balance, error) {
+	return u.modifyBalance(ctx, "ModifyBalance", guild, user, update)
+}
+
+// modifyBalance is ModifyBalanceContext with the operation named after the
+// method that was called, as SetBalance and UpdateBalance share it.
+func (u *Client) modifyBalance(ctx context.Context, name, guild, user string, update *BalanceUpdate) (Balance, error) {
+	if guild == "" || user == "" {
+		return Balance{}, fmt.Errorf("%w: missing guild or user ID", ErrInvalidUpdate)
+	}
+	if err := update.Validate(); err != nil {
+		return Balance{}, err
+	}
+	value, err := update.payload()
+	if err != nil {
+		return Balance{}, err
+	}
+	op := &Operation{Name: name, Method: update.method(), Path: fmt.Sprintf("/guilds/%v/users/%v", guild, user), GuildID: guild, UserID: user, Payload: value}
+	res, err := u.invoke(ctx, op, func(ctx context.Context, op *Operation) (interface{}, error) {
+		if update.key != "" {
+			return u.modifyOnce(ctx, op, update)
+		}
+		return u.sendUpdate(ctx, op)
+	})
+	bal, _ := res.(Balance)
+	return bal, err
+}
+
+func (u *Client) sendUpdate(ctx context.Context, op *Operation) (Balance, error) {
+	data, err := u.perform(ctx, op)
+	if err != nil {
+		return Balance{}, err
+	}
+	return decodeBalance(data)
+}
+
+// amountOf converts the loosely typed amounts SetBalance accepts. ok is false
+// for nil, meaning the field is left alone.
+func amountOf(v interface{}) (a Amount, ok bool, err error) {
+	switch v := v.(type) {
+	case nil:
+		return Amount{}, false, nil
+	case Amount:
+		return v, true, nil
+	case *big.Int:
+		return NewAmountFromBig(v), true, nil
+	case int:
+		return NewAmount(int64(v)), true, nil
+	case int8:
+		return NewAmount(int64(v)), true, nil
+	case int16:
+		return NewAmount(int64(v)), true, nil
+	case int32:
+		return NewAmount(int64(v)), true, nil
+	case int64:
+		return NewAmount(v), true, nil
+	case uint:
+		return NewAmountFromBig(new(big.Int).SetUint64(uint64(v))), true, nil
+	case uint8:
+		return NewAmount(int64(v)), true, nil
+	case uint16:
+		return NewAmount(int64(v)), true, nil
+	case uint32:
+		return NewAmount(int64(v)), true, nil
+	case uint64:
+		return NewAmountFromBig(new(big.Int).SetUint64(v)), true, nil
+	case string:
+		a, err := ParseAmount(v)
+		if err != nil {
+			return Amount{}, false, fmt.Errorf("%w: %v", ErrInvalidUpdate, err)
+		}
+		return a, true, nil
+	}
+	return Amount{}, false, fmt.Errorf("%w: unsupported amount type %T", ErrInvalidUpdate, v)
+}
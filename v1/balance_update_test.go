@@ -0,0 +1,122 @@
+package v1
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+// Records the method and body of each request and replies with testBalance.
+func setRecordingClient(t *testing.T, calls *int32, method, body *string) *http.Client {
+	return NewTestClient(func(req *http.Request) *http.Response {
+		atomic.AddInt32(calls, 1)
+		b, err := ioutil.ReadAll(req.Body)
+		ok(t, err)
+		*method, *body = req.Method, string(b)
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(testBalance)),
+			Header:     make(http.Header),
+		}
+	})
+}
+
+func TestBalanceUpdateValidate(t *testing.T) {
+	ok(t, NewBalanceUpdate().SetCash(NewAmount(5)).Validate())
+	ok(t, NewBalanceUpdate().AddCash(NewAmount(5)).AddBank(NewAmount(-5)).Validate())
+	ok(t, NewBalanceUpdate().InfiniteBank().SetCash(NewAmount(0)).Validate())
+
+	for name, update := range map[string]*BalanceUpdate{
+		"nil":         nil,
+		"empty":       NewBalanceUpdate().Reason("nothing"),
+		"set and add": NewBalanceUpdate().SetCash(NewAmount(5)).AddBank(NewAmount(5)),
+		"cash twice":  NewBalanceUpdate().AddCash(NewAmount(5)).AddCash(NewAmount(5)),
+		"bank twice":  NewBalanceUpdate().InfiniteBank().SetBank(NewAmount(5)),
+	} {
+		err := update.Validate()
+		assert(t, errors.Is(err, ErrInvalidUpdate), "%s: expected ErrInvalidUpdate, got %v", name, err)
+	}
+}
+
+func TestBalanceUpdateApply(t *testing.T) {
+	before := Balance{Rank: 3, UserID: "398197113495748626", Cash: NewAmount(50), Bank: NewAmount(500), Total: NewAmount(550)}
+	equals(t, Balance{3, "398197113495748626", NewAmount(40), NewAmount(600), NewAmount(640)}, NewBalanceUpdate().AddCash(NewAmount(-10)).AddBank(NewAmount(100)).Apply(before))
+	equals(t, Balance{3, "398197113495748626", NewAmount(50), Inf(1), Inf(1)}, NewBalanceUpdate().InfiniteBank().Apply(before))
+}
+
+func TestModifyBalanceSendsSetAsPut(t *testing.T) {
+	var calls int32
+	var method, body string
+	api := Custom("token", setRecordingClient(t, &calls, &method, &body))
+
+	data, err := api.ModifyBalance("411898639737421824", "398197113495748626", NewBalanceUpdate().SetCash(NewAmount(50)).InfiniteBank().Reason("Admin"))
+	ok(t, err)
+	equals(t, NewAmount(552), data.Total)
+	equals(t, "PUT", method)
+	equals(t, `{"cash":50,"bank":"Infinity","reason":"Admin"}`, body)
+}
+
+func TestModifyBalanceSendsAddAsPatch(t *testing.T) {
+	var calls int32
+	var method, body string
+	api := Custom("token", setRecordingClient(t, &calls, &method, &body))
+
+	_, err := api.ModifyBalance("411898639737421824", "398197113495748626", NewBalanceUpdate().AddBank(NewAmount(-20)))
+	ok(t, err)
+	equals(t, "PATCH", method)
+	equals(t, `{"bank":-20}`, body)
+}
+
+func TestModifyBalanceSendsNothingWhenInvalid(t *testing.T) {
+	var calls int32
+	var method, body string
+	api := Custom("token", setRecordingClient(t, &calls, &method, &body))
+
+	_, err := api.ModifyBalance("411898639737421824", "398197113495748626", NewBalanceUpdate().SetCash(NewAmount(1)).AddBank(NewAmount(1)))
+	assert(t, errors.Is(err, ErrInvalidUpdate), "expected ErrInvalidUpdate, got %v", err)
+	_, err = api.ModifyBalance("", "398197113495748626", NewBalanceUpdate().SetCash(NewAmount(1)))
+	assert(t, errors.Is(err, ErrInvalidUpdate), "expected ErrInvalidUpdate, got %v", err)
+	equals(t, int32(0), calls)
+}
+
+func TestSetBalanceSendsInt64InsteadOfDroppingIt(t *testing.T) {
+	var calls int32
+	var method, body string
+	api := Custom("token", setRecordingClient(t, &calls, &method, &body))
+
+	_, err := api.SetBalance("411898639737421824", "398197113495748626", int64(50), "-Infinity", nil)
+	ok(t, err)
+	equals(t, "PUT", method)
+	equals(t, `{"cash":50,"bank":"-Infinity","reason":"No reason provided."}`, body)
+}
+
+func TestSetBalanceRejectsUnsupportedValues(t *testing.T) {
+	var calls int32
+	var method, body string
+	api := Custom("token", setRecordingClient(t, &calls, &method, &body))
+
+	for _, args := range [][3]interface{}{
+		{50.5, nil, nil},
+		{nil, "lots", nil},
+		{nil, nil, nil},
+		{50, nil, 42},
+	} {
+		_, err := api.SetBalance("411898639737421824", "398197113495748626", args[0], args[1], args[2])
+		assert(t, errors.Is(err, ErrInvalidUpdate), "%v: expected ErrInvalidUpdate, got %v", args, err)
+	}
+	equals(t, int32(0), calls)
+}
+
+func TestUpdateBalanceSendsDeltas(t *testing.T) {
+	var calls int32
+	var method, body string
+	api := Custom("token", setRecordingClient(t, &calls, &method, &body))
+
+	_, err := api.UpdateBalance("411898639737421824", "398197113495748626", -40, 0, "Just testing")
+	ok(t, err)
+	equals(t, "PATCH", method)
+	equals(t, `{"cash":-40,"bank":0,"reason":"Just testing"}`, body)
+}
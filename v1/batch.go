@@ -0,0 +1,186 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// DefaultBatchConcurrency is how many updates BatchUpdate sends at once
+// unless BatchOptions.Concurrency is set.
+const DefaultBatchConcurrency = 4
+
+// BalanceDelta is one user's change in a BatchUpdate: Cash and Bank are
+// added to the user's balance, and may be negative.
+type BalanceDelta struct {
+	UserID string
+	Cash   Amount
+	Bank   Amount
+	Reason string
+	// IdempotencyKey, if set, makes rerunning the batch safe: see
+	// BalanceUpdate.IdempotencyKey.
+	IdempotencyKey string
+}
+
+func (d BalanceDelta) update() *BalanceUpdate {
+	update := NewBalanceUpdate().Reason(d.Reason).IdempotencyKey(d.IdempotencyKey)
+	if d.Cash.Sign() != 0 {
+		update.AddCash(d.Cash)
+	}
+	if d.Bank.Sign() != 0 {
+		update.AddBank(d.Bank)
+	}
+	return update
+}
+
+// BatchOptions configures a BatchUpdate.
+type BatchOptions struct {
+	// Concurrency is the most updates in flight at once. Defaults to
+	// DefaultBatchConcurrency. The client's rate limiter still applies, so
+	// raising it only helps while the bucket has room.
+	Concurrency int
+}
+
+// BatchStatus is the outcome of one update in a BatchUpdate.
+type BatchStatus int
+
+const (
+	// BatchSucceeded means the update was applied.
+	BatchSucceeded BatchStatus = iota
+	// BatchFailed means the API rejected the update, or it was invalid.
+	// Sending it again won't help.
+	BatchFailed
+	// BatchRetriable means the update failed for a reason that may pass:
+	// a rate limit, a server or network error, another update with the same
+	// idempotency key being in flight, or the batch being cancelled. Rate
+	// limited and cancelled updates were not applied, but server and network
+	// errors are ambiguous, so re-read the balance (or use an idempotency
+	// key) before sending those again.
+	BatchRetriable
+)
+
+func (s BatchStatus) String() string {
+	switch s {
+	case BatchSucceeded:
+		return "succeeded"
+	case BatchFailed:
+		return "failed"
+	case BatchRetriable:
+		return "retriable"
+	}
+	return "unknown"
+}
+
+// BatchResult is the outcome of one BalanceDelta.
+type BatchResult struct {
+	Delta  BalanceDelta
+	Status BatchStatus
+	// Balance is the user's new balance when Status is BatchSucceeded.
+	Balance Balance
+	Err     error
+}
+
+// BatchReport holds the outcome of every delta in a BatchUpdate, in the
+// order they were given.
+type BatchReport struct {
+	Results []BatchResult
+}
+
+// Succeeded returns the results of the updates that were applied.
+func (r BatchReport) Succeeded() []BatchResult {
+	return r.filter(BatchSucceeded)
+}
+
+// Failed returns the results of the updates that were rejected.
+func (r BatchReport) Failed() []BatchResult {
+	return r.filter(BatchFailed)
+}
+
+// Retriable returns the results of the updates that may succeed if tried
+// again.
+func (r BatchReport) Retriable() []BatchResult {
+	return r.filter(BatchRetriable)
+}
+
+func (r BatchReport) filter(status BatchStatus) []BatchResult {
+	var results []BatchResult
+	for _, res := range r.Results {
+		if res.Status == status {
+			results = append(results, res)
+		}
+	}
+	return results
+}
+
+// BatchUpdate adds each delta to its user's balance in a guild, sending up
+// to opts.Concurrency updates at once, and reports how each one went:
+//
+//	report := api.BatchUpdate(guildID, []v1.BalanceDelta{
+//		{UserID: "1", Cash: v1.NewAmount(100), Reason: "Payday"},
+//		{UserID: "2", Cash: v1.NewAmount(100), Reason: "Payday"},
+//	}, v1.BatchOptions{})
+//	for _, res := range report.Failed() {
+//		log.Printf("paying %s: %v", res.Delta.UserID, res.Err)
+//	}
+//
+// A failed update doesn't stop the others. A delta whose Cash and Bank are
+// both zero fails with ErrInvalidUpdate.
+func (u *Client) BatchUpdate(guild string, deltas []BalanceDelta, opts BatchOptions) BatchReport {
+	return u.BatchUpdateContext(context.Background(), guild, deltas, opts)
+}
+
+// BatchUpdateContext is like BatchUpdate but bound to ctx. Once ctx is done,
+// updates not yet sent are reported as BatchRetriable with ctx's error.
+func (u *Client) BatchUpdateContext(ctx context.Context, guild string, deltas []BalanceDelta, opts BatchOptions) BatchReport {
+	n := opts.Concurrency
+	if n <= 0 {
+		n = DefaultBatchConcurrency
+	}
+	results := make([]BatchResult, len(deltas))
+	sem := make(chan struct{}, n)
+	var wg sync.WaitGroup
+	for i, d := range deltas {
+		res := &results[i]
+		res.Delta = d
+		if err := ctx.Err(); err != nil {
+			res.Status, res.Err = BatchRetriable, err
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			res.Status, res.Err = BatchRetriable, ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			res.Balance, res.Err = u.ModifyBalanceContext(ctx, guild, res.Delta.UserID, res.Delta.update())
+			res.Status = batchStatus(res.Err)
+		}()
+	}
+	wg.Wait()
+	return BatchReport{Results: results}
+}
+
+func batchStatus(err error) BatchStatus {
+	var rateErr *RateLimitError
+	var apiErr *APIError
+	switch {
+	case err == nil:
+		return BatchSucceeded
+	case errors.As(err, &rateErr):
+		return BatchRetriable
+	case errors.Is(err, ErrInvalidUpdate), errors.Is(err, ErrUnreconciled):
+		return BatchFailed
+	case errors.As(err, &apiErr):
+		if apiErr.StatusCode >= 500 {
+			return BatchRetriable
+		}
+		return BatchFailed
+	}
+	return BatchRetriable
+}
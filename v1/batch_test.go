@@ -0,0 +1,90 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Replies to balance updates by user: "limited" is rate limited, "bad" is
+// rejected, "down" fails with a server error and the rest succeed. It tracks
+// the most requests in flight at once.
+func newBatchServer(maxInFlight *int32) *httptest.Server {
+	var inFlight int32
+	var mu sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		mu.Lock()
+		if n > *maxInFlight {
+			*maxInFlight = n
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+
+		switch user := path.Base(r.URL.Path); user {
+		case "limited":
+			w.WriteHeader(429)
+			w.Write([]byte(`{"message":"You are being rate limited.","retry_after":1}`))
+		case "bad":
+			w.WriteHeader(400)
+			w.Write([]byte(`{"error":"400: Bad Request","message":"Invalid user"}`))
+		case "down":
+			w.WriteHeader(502)
+		default:
+			w.Write([]byte(`{"rank":"1","user_id":"` + user + `","cash":100,"bank":0,"total":100}`))
+		}
+	}))
+}
+
+func TestBatchUpdateReportsEachUser(t *testing.T) {
+	var maxInFlight int32
+	server := newBatchServer(&maxInFlight)
+	defer server.Close()
+	api, err := NewClient("token", WithBaseURL(server.URL))
+	ok(t, err)
+
+	deltas := []BalanceDelta{
+		{UserID: "1", Cash: NewAmount(100), Reason: "Payday"},
+		{UserID: "limited", Cash: NewAmount(100)},
+		{UserID: "2", Bank: NewAmount(5)},
+		{UserID: "bad", Cash: NewAmount(100)},
+		{UserID: "down", Cash: NewAmount(100)},
+		{UserID: "3"},
+		{UserID: "4", Cash: NewAmount(-1)},
+	}
+	report := api.BatchUpdate("411898639737421824", deltas, BatchOptions{Concurrency: 2})
+	equals(t, len(deltas), len(report.Results))
+	var statuses []string
+	for i, res := range report.Results {
+		equals(t, deltas[i], res.Delta)
+		statuses = append(statuses, res.Status.String())
+	}
+	equals(t, []string{"succeeded", "retriable", "succeeded", "failed", "retriable", "failed", "succeeded"}, statuses)
+	equals(t, "2", report.Succeeded()[1].Balance.UserID)
+	equals(t, 2, len(report.Failed()))
+	assert(t, errors.Is(report.Failed()[1].Err, ErrInvalidUpdate), "expected ErrInvalidUpdate, got %v", report.Failed()[1].Err)
+	equals(t, 2, len(report.Retriable()))
+	assert(t, maxInFlight <= 2, "expected at most 2 requests in flight, got %d", maxInFlight)
+}
+
+func TestBatchUpdateStopsSendingOnceCancelled(t *testing.T) {
+	var maxInFlight int32
+	server := newBatchServer(&maxInFlight)
+	defer server.Close()
+	api, err := NewClient("token", WithBaseURL(server.URL))
+	ok(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report := api.BatchUpdateContext(ctx, "411898639737421824", []BalanceDelta{{UserID: "1", Cash: NewAmount(1)}}, BatchOptions{})
+	equals(t, BatchRetriable, report.Results[0].Status)
+	equals(t, context.Canceled, report.Results[0].Err)
+	equals(t, int32(0), maxInFlight)
+}
@@ -0,0 +1,274 @@
+package v1
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// BalanceCache stores balances for a CachedClient. Keys are opaque strings
+// made from the guild and user IDs. Implement it to keep balances in a
+// shared store such as Redis; LRUCache keeps them in memory.
+//
+// Implementations must be safe for concurrent use.
+type BalanceCache interface {
+	// Get returns the balance stored under key, and whether there is one
+	// that hasn't expired.
+	Get(ctx context.Context, key string) (Balance, bool, error)
+	// Set stores b under key for ttl.
+	Set(ctx context.Context, key string, b Balance, ttl time.Duration) error
+	// Delete removes key.
+	Delete(ctx context.Context, key string) error
+}
+
+// CacheOptions configures a CachedClient.
+type CacheOptions struct {
+	// TTL is how long a balance is served from the cache. Defaults to 30s.
+	TTL time.Duration
+	// Store holds the balances. Defaults to an LRUCache of 1000 balances.
+	Store BalanceCache
+}
+
+// CachedClient wraps an API with a read-through balance cache: GetBalance
+// is answered from the cache while the entry is fresh, and SetBalance,
+// UpdateBalance and ModifyBalance replace the entry with the balance they
+// return. Every other method goes straight to the wrapped API.
+//
+//	api, _ := v1.NewClient(token)
+//	cached := v1.NewCachedClient(api, v1.CacheOptions{TTL: time.Minute})
+//	bal, err := cached.GetBalance(guildID, userID)
+//
+// The cache is best effort: if the store fails the call falls through to the
+// API. A GetBalance that was already fetching when a write returned doesn't
+// overwrite the balance the write cached. Changes made elsewhere, such as by
+// the bot's own commands, only show once the entry expires or Invalidate is
+// called.
+type CachedClient struct {
+	API
+	ttl   time.Duration
+	store BalanceCache
+
+	// mu guards reads, which orders read-through fills against writes so
+	// that a fetch that started before a write can't cache the balance from
+	// before it. It is never held while calling the store.
+	mu    sync.Mutex
+	reads map[string]*pendingRead
+}
+
+// pendingRead tracks the GetBalance fetches in flight for a key. gen is
+// bumped by every write to the key while they are.
+type pendingRead struct {
+	readers int
+	gen     uint64
+}
+
+var _ API = (*CachedClient)(nil)
+
+// NewCachedClient returns api with a balance cache in front of it.
+func NewCachedClient(api API, opts CacheOptions) *CachedClient {
+	c := &CachedClient{API: api, ttl: opts.TTL, store: opts.Store, reads: make(map[string]*pendingRead)}
+	if c.ttl <= 0 {
+		c.ttl = 30 * time.Second
+	}
+	if c.store == nil {
+		c.store = NewLRUCache(1000)
+	}
+	return c
+}
+
+func cacheKey(guild, user string) string {
+	return guild + "/" + user
+}
+
+// Invalidate drops the cached balance of a user, so the next GetBalance
+// fetches it from the API.
+func (c *CachedClient) Invalidate(ctx context.Context, guild, user string) error {
+	key := cacheKey(guild, user)
+	c.bump(key)
+	return c.store.Delete(ctx, key)
+}
+
+// bump marks fetches of key in flight as stale.
+func (c *CachedClient) bump(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if p, ok := c.reads[key]; ok {
+		p.gen++
+	}
+}
+
+// stale reports whether the key p tracks was written after gen was read.
+func (c *CachedClient) stale(p *pendingRead, gen uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return p.gen != gen
+}
+
+// GetBalance fetches a user's balance, from the cache if it is fresh.
+func (c *CachedClient) GetBalance(guild, user string) (Balance, error) {
+	return c.GetBalanceContext(context.Background(), guild, user)
+}
+
+// GetBalanceContext is like GetBalance but bound to ctx.
+func (c *CachedClient) GetBalanceContext(ctx context.Context, guild, user string) (Balance, error) {
+	key := cacheKey(guild, user)
+	if bal, ok, err := c.store.Get(ctx, key); err == nil && ok {
+		return bal, nil
+	}
+	c.mu.Lock()
+	p, ok := c.reads[key]
+	if !ok {
+		p = &pendingRead{}
+		c.reads[key] = p
+	}
+	p.readers++
+	gen := p.gen
+	c.mu.Unlock()
+
+	bal, err := c.API.GetBalanceContext(ctx, guild, user)
+	// A write since the fetch started has cached something newer. One that
+	// lands while the balance is being stored may have been overwritten by
+	// it, so the entry is dropped instead.
+	if err == nil && !c.stale(p, gen) {
+		c.store.Set(ctx, key, bal, c.ttl)
+		if c.stale(p, gen) {
+			c.store.Delete(ctx, key)
+		}
+	}
+
+	c.mu.Lock()
+	if p.readers--; p.readers == 0 {
+		delete(c.reads, key)
+	}
+	c.mu.Unlock()
+	if err != nil {
+		return Balance{}, err
+	}
+	return bal, nil
+}
+
+// SetBalance is like Client.SetBalance, and caches the new balance.
+func (c *CachedClient) SetBalance(guild, user string, cash, bank, reason interface{}) (Balance, error) {
+	return c.SetBalanceContext(context.Background(), guild, user, cash, bank, reason)
+}
+
+// SetBalanceContext is like SetBalance but bound to ctx.
+func (c *CachedClient) SetBalanceContext(ctx context.Context, guild, user string, cash, bank, reason interface{}) (Balance, error) {
+	bal, err := c.API.SetBalanceContext(ctx, guild, user, cash, bank, reason)
+	return c.remember(ctx, guild, user, bal, err)
+}
+
+// UpdateBalance is like Client.UpdateBalance, and caches the new balance.
+func (c *CachedClient) UpdateBalance(guild, user string, cash, bank int, reason interface{}) (Balance, error) {
+	return c.UpdateBalanceContext(context.Background(), guild, user, cash, bank, reason)
+}
+
+// UpdateBalanceContext is like UpdateBalance but bound to ctx.
+func (c *CachedClient) UpdateBalanceContext(ctx context.Context, guild, user string, cash, bank int, reason interface{}) (Balance, error) {
+	bal, err := c.API.UpdateBalanceContext(ctx, guild, user, cash, bank, reason)
+	return c.remember(ctx, guild, user, bal, err)
+}
+
+// ModifyBalance is like Client.ModifyBalance, and caches the new balance.
+func (c *CachedClient) ModifyBalance(guild, user string, update *BalanceUpdate) (Balance, error) {
+	return c.ModifyBalanceContext(context.Background(), guild, user, update)
+}
+
+// ModifyBalanceContext is like ModifyBalance but bound to ctx.
+func (c *CachedClient) ModifyBalanceContext(ctx context.Context, guild, user string, update *BalanceUpdate) (Balance, error) {
+	bal, err := c.API.ModifyBalanceContext(ctx, guild, user, update)
+	return c.remember(ctx, guild, user, bal, err)
+}
+
+// remember caches the balance a write returned, or drops the entry if the
+// write failed, since it may still have been applied.
+func (c *CachedClient) remember(ctx context.Context, guild, user string, bal Balance, err error) (Balance, error) {
+	key := cacheKey(guild, user)
+	c.bump(key)
+	if err != nil {
+		c.store.Delete(ctx, key)
+		return Balance{}, err
+	}
+	c.store.Set(ctx, key, bal, c.ttl)
+	return bal, nil
+}
+
+// LRUCache is an in-memory BalanceCache that holds up to a fixed number of
+// balances, evicting the least recently used.
+type LRUCache struct {
+	size    int
+	mu      sync.Mutex
+	order   *list.List // of *lruEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key     string
+	balance Balance
+	expires time.Time
+}
+
+// NewLRUCache returns a cache holding up to size balances.
+func NewLRUCache(size int) *LRUCache {
+	if size < 1 {
+		size = 1
+	}
+	return &LRUCache{size: size, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// Get implements BalanceCache.
+func (c *LRUCache) Get(ctx context.Context, key string) (Balance, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return Balance{}, false, nil
+	}
+	e := el.Value.(*lruEntry)
+	if time.Now().After(e.expires) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return Balance{}, false, nil
+	}
+	c.order.MoveToFront(el)
+	return e.balance, true, nil
+}
+
+// Set implements BalanceCache.
+func (c *LRUCache) Set(ctx context.Context, key string, b Balance, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := &lruEntry{key: key, balance: b, expires: time.Now().Add(ttl)}
+	if el, ok := c.entries[key]; ok {
+		el.Value = e
+		c.order.MoveToFront(el)
+		return nil
+	}
+	c.entries[key] = c.order.PushFront(e)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+	return nil
+}
+
+// Delete implements BalanceCache.
+func (c *LRUCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.order.Remove(el)
+		delete(c.entries, key)
+	}
+	return nil
+}
+
+// Len returns the number of balances held, including expired ones not yet
+// evicted.
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,158 @@
+package v1
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCachedClientServesFreshBalancesFromCache(t *testing.T) {
+	s := &balanceServer{cash: 10}
+	cached := NewCachedClient(newIdempotencyClient(t, s), CacheOptions{TTL: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		bal, err := cached.GetBalance("411898639737421824", "398197113495748626")
+		ok(t, err)
+		equals(t, NewAmount(10), bal.Cash)
+	}
+	equals(t, 1, s.reads)
+
+	ok(t, cached.Invalidate(context.Background(), "411898639737421824", "398197113495748626"))
+	_, err := cached.GetBalance("411898639737421824", "398197113495748626")
+	ok(t, err)
+	equals(t, 2, s.reads)
+}
+
+func TestCachedClientUpdatesEntryOnWrite(t *testing.T) {
+	s := &balanceServer{cash: 10}
+	cached := NewCachedClient(newIdempotencyClient(t, s), CacheOptions{})
+
+	_, err := cached.GetBalance("411898639737421824", "398197113495748626")
+	ok(t, err)
+	_, err = cached.UpdateBalance("411898639737421824", "398197113495748626", 5, 0, nil)
+	ok(t, err)
+	bal, err := cached.GetBalance("411898639737421824", "398197113495748626")
+	ok(t, err)
+	equals(t, NewAmount(15), bal.Cash)
+	equals(t, 1, s.reads)
+
+	// A failed write may have been applied, so the entry is dropped.
+	s.applyThenFail = true
+	_, err = cached.SetBalance("411898639737421824", "398197113495748626", 1, nil, nil)
+	assert(t, err != nil, "expected an error")
+	bal, err = cached.GetBalance("411898639737421824", "398197113495748626")
+	ok(t, err)
+	equals(t, NewAmount(1), bal.Cash)
+	equals(t, 2, s.reads)
+}
+
+func TestCachedClientExpiresEntries(t *testing.T) {
+	s := &balanceServer{cash: 10}
+	cached := NewCachedClient(newIdempotencyClient(t, s), CacheOptions{TTL: time.Millisecond})
+
+	_, err := cached.GetBalance("411898639737421824", "398197113495748626")
+	ok(t, err)
+	time.Sleep(5 * time.Millisecond)
+	_, err = cached.GetBalance("411898639737421824", "398197113495748626")
+	ok(t, err)
+	equals(t, 2, s.reads)
+}
+
+func TestLRUCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	cache := NewLRUCache(2)
+	ok(t, cache.Set(ctx, "a", Balance{UserID: "a"}, time.Minute))
+	ok(t, cache.Set(ctx, "b", Balance{UserID: "b"}, time.Minute))
+	_, found, _ := cache.Get(ctx, "a")
+	equals(t, true, found)
+	ok(t, cache.Set(ctx, "c", Balance{UserID: "c"}, time.Minute))
+
+	_, found, _ = cache.Get(ctx, "b")
+	equals(t, false, found)
+	bal, found, _ := cache.Get(ctx, "a")
+	equals(t, true, found)
+	equals(t, "a", bal.UserID)
+	equals(t, 2, cache.Len())
+}
+
+// slowReadAPI answers the first GetBalance with the balance from before an
+// update, once release is closed.
+type slowReadAPI struct {
+	API
+	reads   int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func (a *slowReadAPI) GetBalanceContext(ctx context.Context, guild, user string) (Balance, error) {
+	if atomic.AddInt32(&a.reads, 1) == 1 {
+		close(a.started)
+		<-a.release
+		return Balance{UserID: user, Cash: NewAmount(10), Total: NewAmount(10)}, nil
+	}
+	return Balance{UserID: user, Cash: NewAmount(15), Total: NewAmount(15)}, nil
+}
+
+func (a *slowReadAPI) UpdateBalanceContext(ctx context.Context, guild, user string, cash, bank int, reason interface{}) (Balance, error) {
+	return Balance{UserID: user, Cash: NewAmount(15), Total: NewAmount(15)}, nil
+}
+
+func TestCachedClientKeepsWriteOverSlowerRead(t *testing.T) {
+	api := &slowReadAPI{started: make(chan struct{}), release: make(chan struct{})}
+	cached := NewCachedClient(api, CacheOptions{TTL: time.Minute})
+
+	done := make(chan Balance)
+	go func() {
+		bal, _ := cached.GetBalance("411898639737421824", "398197113495748626")
+		done <- bal
+	}()
+	<-api.started
+	_, err := cached.UpdateBalance("411898639737421824", "398197113495748626", 5, 0, nil)
+	ok(t, err)
+	close(api.release)
+	equals(t, NewAmount(10), (<-done).Cash)
+
+	bal, err := cached.GetBalance("411898639737421824", "398197113495748626")
+	ok(t, err)
+	equals(t, NewAmount(15), bal.Cash)
+	equals(t, int32(1), atomic.LoadInt32(&api.reads))
+}
+
+// slowStore is an LRUCache whose first Set waits for release, like a
+// remote store on a slow connection.
+type slowStore struct {
+	*LRUCache
+	sets    int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *slowStore) Set(ctx context.Context, key string, b Balance, ttl time.Duration) error {
+	if atomic.AddInt32(&s.sets, 1) == 1 {
+		close(s.started)
+		<-s.release
+	}
+	return s.LRUCache.Set(ctx, key, b, ttl)
+}
+
+func TestCachedClientDoesNotHoldOtherKeysBehindSlowStore(t *testing.T) {
+	store := &slowStore{LRUCache: NewLRUCache(10), started: make(chan struct{}), release: make(chan struct{})}
+	api := &slowReadAPI{started: make(chan struct{}), release: make(chan struct{})}
+	close(api.release)
+	cached := NewCachedClient(api, CacheOptions{TTL: time.Minute, Store: store})
+
+	go cached.UpdateBalance("411898639737421824", "398197113495748626", 5, 0, nil)
+	<-store.started
+	done := make(chan error)
+	go func() {
+		done <- cached.Invalidate(context.Background(), "411898639737421824", "116293018742554625")
+	}()
+	select {
+	case err := <-done:
+		ok(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Invalidate waited for another user's store write")
+	}
+	close(store.release)
+}
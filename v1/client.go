@@ -0,0 +1,143 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the API root requests are sent to unless WithBaseURL is
+// used.
+const DefaultBaseURL = "https://unbelievable.pizza/api/v1"
+
+// DefaultUserAgent is sent with every request unless WithUserAgent is used.
+const DefaultUserAgent = "unb-api-go/v1 (+https://github.com/BaileyJM02/unb-api-go)"
+
+// Client talks to the UnbelievaBoat API. Create one with NewClient; it is
+// safe for concurrent use by multiple goroutines.
+type Client struct {
+	token     string
+	baseURL   string
+	client    *http.Client
+	userAgent string
+	timeout   time.Duration
+	retry     RetryPolicy
+	logger    *slog.Logger
+	logLevels LogLevels
+	tracer    Tracer
+	metrics   Metrics
+	limiter   *rateLimiter
+
+	idempotency IdempotencyStore
+	middleware  []Middleware
+}
+
+// Option configures a Client created by NewClient.
+type Option func(*Client) error
+
+// NewClient returns a client authenticated with token, which can be found at
+// https://unb.pizza/api/docs.
+//
+//	api, err := v1.NewClient(token,
+//		v1.WithTimeout(3*time.Second),
+//		v1.WithRetryPolicy(v1.DefaultRetryPolicy()),
+//	)
+func NewClient(token string, opts ...Option) (*Client, error) {
+	c := &Client{
+		token:     token,
+		baseURL:   DefaultBaseURL,
+		client:    &http.Client{},
+		userAgent: DefaultUserAgent,
+		logLevels: DefaultLogLevels(),
+		limiter:   newRateLimiter(),
+
+		idempotency: NewMemoryIdempotencyStore(24 * time.Hour),
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// WithHTTPClient sets the HTTP client requests are sent with.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) error {
+		if client == nil {
+			return errors.New("v1: nil http.Client")
+		}
+		c.client = client
+		return nil
+	}
+}
+
+// WithBaseURL sets the API root requests are sent to, such as a mock server
+// or recording proxy. It must be an absolute http or https URL without a
+// query or fragment; a trailing slash is ignored.
+func WithBaseURL(rawURL string) Option {
+	return func(c *Client) error {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return fmt.Errorf("v1: invalid base URL: %w", err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("v1: invalid base URL %q: scheme must be http or https", rawURL)
+		}
+		if u.Host == "" {
+			return fmt.Errorf("v1: invalid base URL %q: missing host", rawURL)
+		}
+		if u.RawQuery != "" || u.Fragment != "" {
+			return fmt.Errorf("v1: invalid base URL %q: must not have a query or fragment", rawURL)
+		}
+		c.baseURL = strings.TrimRight(u.String(), "/")
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) error {
+		c.userAgent = userAgent
+		return nil
+	}
+}
+
+// WithTimeout bounds how long each call, including any retries and rate
+// limit waits, may take. It applies on top of any deadline on the context
+// passed to the call. Zero means no timeout.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) error {
+		if d < 0 {
+			return errors.New("v1: negative timeout")
+		}
+		c.timeout = d
+		return nil
+	}
+}
+
+// WithRetryPolicy sets how failed requests are retried. By default they are
+// not retried.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) error {
+		if p.MaxRetries < 0 {
+			return errors.New("v1: negative MaxRetries")
+		}
+		c.retry = p
+		return nil
+	}
+}
+
+// WithLogger sets the logger the client reports each call to, along with
+// retries and rate limit waits; see LogLevels. By default nothing is logged.
+// The token is never logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) error {
+		c.logger = logger
+		return nil
+	}
+}
@@ -0,0 +1,107 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewClientAppliesOptions(t *testing.T) {
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		equals(t, "token", req.Header.Get("Authorization"))
+		equals(t, "my-bot/1.0", req.Header.Get("User-Agent"))
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(testBalance)),
+			Header:     make(http.Header),
+		}
+	})
+
+	api, err := NewClient("token", WithHTTPClient(client), WithUserAgent("my-bot/1.0"))
+	ok(t, err)
+	data, err := api.GetBalance("411898639737421824", "398197113495748626")
+	ok(t, err)
+	equals(t, NewAmount(552), data.Total)
+}
+
+func TestNewClientSendsDefaultUserAgent(t *testing.T) {
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		equals(t, DefaultUserAgent, req.Header.Get("User-Agent"))
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(testBalance)),
+			Header:     make(http.Header),
+		}
+	})
+
+	_, err := Custom("token", client).GetBalance("411898639737421824", "398197113495748626")
+	ok(t, err)
+}
+
+func TestCustomWithNilClientUsesDefault(t *testing.T) {
+	api := Custom("token", nil)
+	equals(t, false, api.client == nil)
+}
+
+func TestNewClientRejectsInvalidOptions(t *testing.T) {
+	_, err := NewClient("token", WithHTTPClient(nil))
+	assert(t, err != nil, "expected an error for a nil http.Client")
+	_, err = NewClient("token", WithTimeout(-time.Second))
+	assert(t, err != nil, "expected an error for a negative timeout")
+	_, err = NewClient("token", WithRetryPolicy(RetryPolicy{MaxRetries: -1}))
+	assert(t, err != nil, "expected an error for negative retries")
+}
+
+func TestWithTimeoutBoundsEachCall(t *testing.T) {
+	api, err := NewClient("token", WithHTTPClient(setHangingClient()), WithTimeout(10*time.Millisecond))
+	ok(t, err)
+
+	_, err = api.GetBalance("411898639737421824", "398197113495748626")
+	equals(t, context.DeadlineExceeded, err)
+}
+
+func TestWithLoggerReportsRetries(t *testing.T) {
+	var calls int32
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	api, err := NewClient("token",
+		WithHTTPClient(setSequenceClient(&calls, cannedResponse{429, `{"message":"You are being rate limited.","retry_after":1}`}, cannedResponse{200, testBalance})),
+		WithRetryPolicy(RetryPolicy{MaxRetries: 1}),
+		WithLogger(logger),
+	)
+	ok(t, err)
+
+	_, err = api.GetBalance("411898639737421824", "398197113495748626")
+	ok(t, err)
+	assert(t, strings.Contains(buf.String(), "unb: retrying request"), "expected a retry to be logged, got %q", buf.String())
+	assert(t, !strings.Contains(buf.String(), "token"), "token leaked into logs: %q", buf.String())
+	equals(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestWithBaseURLSendsRequestsToServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		equals(t, "/api/v1/guilds/411898639737421824/users/398197113495748626", r.URL.Path)
+		w.Write([]byte(testBalance))
+	}))
+	defer server.Close()
+
+	api, err := NewClient("token", WithBaseURL(server.URL+"/api/v1/"))
+	ok(t, err)
+	data, err := api.GetBalance("411898639737421824", "398197113495748626")
+	ok(t, err)
+	equals(t, NewAmount(552), data.Total)
+}
+
+func TestWithBaseURLRejectsInvalidURLs(t *testing.T) {
+	for _, raw := range []string{"", "unbelievable.pizza/api/v1", "ftp://unbelievable.pizza", "http://", "https://unbelievable.pizza/api/v1?x=1", "://bad"} {
+		_, err := NewClient("token", WithBaseURL(raw))
+		assert(t, err != nil, "expected an error for base URL %q", raw)
+	}
+}
@@ -0,0 +1,115 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIError is returned when the API rejects a request, either with a non-2xx
+// status or with an error body such as {"error":"404: Not found","message":"Unknown guild"}.
+// Use errors.As to inspect it:
+//
+//	var apiErr *v1.APIError
+//	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+//		// unknown guild or user
+//	}
+type APIError struct {
+	// StatusCode is the HTTP status of the error. When the API reports an
+	// error in the body of a 2xx response, it is taken from Code instead.
+	StatusCode int
+	// Code is the "error" field of the body, e.g. "401: Unauthorized".
+	// It is empty when the body could not be decoded.
+	Code string
+	// Message is the "message" field of the body, if any.
+	Message string
+	// Body is the raw response body.
+	Body []byte
+}
+
+func (e *APIError) Error() string {
+	code := e.Code
+	if code == "" {
+		code = fmt.Sprintf("%d: %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	if e.Message == "" {
+		return code
+	}
+	return fmt.Sprintf("%v (%v)", code, e.Message)
+}
+
+// RateLimitError is returned when the API answers with 429 Too Many Requests.
+type RateLimitError struct {
+	// Message is the "message" field of the body.
+	Message string
+	// RetryAfter is how long to wait before sending the request again.
+	RetryAfter time.Duration
+	// Body is the raw response body.
+	Body []byte
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%v Retry after: %s", e.Message, e.RetryAfter)
+}
+
+type errorResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+type timeoutResponse struct {
+	Message string `json:"message"`
+	// RetryAfter is in milliseconds.
+	RetryAfter int64 `json:"retry_after"`
+}
+
+// parseError returns the error described by a response, or nil if the
+// response is a success. It never fails on malformed bodies; the raw body is
+// kept on the returned error instead.
+func parseError(resp *http.Response, body []byte) error {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return parseRateLimit(resp, body)
+	}
+	var errResp errorResponse
+	if json.Unmarshal(body, &errResp) == nil && errResp.Error != "" {
+		status := resp.StatusCode
+		if status < 400 {
+			status = statusFromCode(errResp.Error, status)
+		}
+		return &APIError{StatusCode: status, Code: errResp.Error, Message: errResp.Message, Body: body}
+	}
+	if resp.StatusCode >= 400 {
+		return &APIError{StatusCode: resp.StatusCode, Body: body}
+	}
+	return nil
+}
+
+func parseRateLimit(resp *http.Response, body []byte) *RateLimitError {
+	rl := &RateLimitError{Message: "You are being rate limited.", Body: body}
+	var t timeoutResponse
+	if json.Unmarshal(body, &t) == nil && t.RetryAfter > 0 {
+		if t.Message != "" {
+			rl.Message = t.Message
+		}
+		rl.RetryAfter = time.Duration(t.RetryAfter) * time.Millisecond
+		return rl
+	}
+	// Fall back to the standard header, which is in seconds.
+	if secs, err := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64); err == nil && secs > 0 {
+		rl.RetryAfter = time.Duration(secs * float64(time.Second))
+	}
+	return rl
+}
+
+// statusFromCode extracts 404 from codes like "404: Not found".
+func statusFromCode(code string, fallback int) int {
+	if i := strings.IndexByte(code, ':'); i > 0 {
+		if n, err := strconv.Atoi(code[:i]); err == nil {
+			return n
+		}
+	}
+	return fallback
+}
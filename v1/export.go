@@ -0,0 +1,187 @@
+package v1
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ExportFormat is a file format ExportLeaderboard can write.
+type ExportFormat string
+
+const (
+	// ExportCSV writes a header row then one row per entry.
+	ExportCSV ExportFormat = "csv"
+	// ExportJSON writes a single JSON array of entries.
+	ExportJSON ExportFormat = "json"
+	// ExportNDJSON writes one JSON object per line.
+	ExportNDJSON ExportFormat = "ndjson"
+)
+
+// DefaultExportPageSize is how many entries ExportLeaderboard fetches per
+// request unless ExportOptions.PageSize is set.
+const DefaultExportPageSize = 1000
+
+// ExportOptions configures ExportLeaderboard.
+type ExportOptions struct {
+	// Format is the format to write. Defaults to ExportCSV.
+	Format ExportFormat
+	// Sort is SortByTotal, SortByCash or SortByBank. Empty means total.
+	Sort string
+	// PageSize is the number of entries fetched per request. Defaults to
+	// DefaultExportPageSize.
+	PageSize int
+}
+
+// exportRow is how an entry is written. Amounts are written as whole numbers
+// or "Infinity", with flags so that infinite balances can be filtered on
+// without parsing.
+type exportRow struct {
+	Rank          int    `json:"rank"`
+	UserID        string `json:"user_id"`
+	Cash          Amount `json:"cash"`
+	Bank          Amount `json:"bank"`
+	Total         Amount `json:"total"`
+	CashInfinite  bool   `json:"cash_infinite"`
+	BankInfinite  bool   `json:"bank_infinite"`
+	TotalInfinite bool   `json:"total_infinite"`
+}
+
+var exportHeader = []string{"rank", "user_id", "cash", "bank", "total", "cash_infinite", "bank_infinite", "total_infinite"}
+
+func newExportRow(e LeaderboardEntry) exportRow {
+	return exportRow{
+		Rank:          e.Rank,
+		UserID:        e.UserID,
+		Cash:          e.Cash,
+		Bank:          e.Bank,
+		Total:         e.Total,
+		CashInfinite:  e.Cash.IsInf(0),
+		BankInfinite:  e.Bank.IsInf(0),
+		TotalInfinite: e.Total.IsInf(0),
+	}
+}
+
+// LeaderboardWriter writes leaderboard entries to an io.Writer in one of the
+// export formats, one entry at a time. Create one with NewLeaderboardWriter.
+type LeaderboardWriter struct {
+	w      io.Writer
+	format ExportFormat
+	csv    *csv.Writer
+	n      int
+}
+
+// NewLeaderboardWriter returns a writer of entries in format to w.
+func NewLeaderboardWriter(w io.Writer, format ExportFormat) (*LeaderboardWriter, error) {
+	lw := &LeaderboardWriter{w: w, format: format}
+	switch format {
+	case ExportCSV:
+		lw.csv = csv.NewWriter(w)
+	case ExportJSON, ExportNDJSON:
+	default:
+		return nil, fmt.Errorf("v1: unknown export format %q", format)
+	}
+	return lw, nil
+}
+
+// Write writes one entry.
+func (lw *LeaderboardWriter) Write(e LeaderboardEntry) error {
+	row := newExportRow(e)
+	switch lw.format {
+	case ExportCSV:
+		if lw.n == 0 {
+			if err := lw.csv.Write(exportHeader); err != nil {
+				return err
+			}
+		}
+		if err := lw.csv.Write([]string{
+			strconv.Itoa(row.Rank), row.UserID,
+			row.Cash.String(), row.Bank.String(), row.Total.String(),
+			strconv.FormatBool(row.CashInfinite), strconv.FormatBool(row.BankInfinite), strconv.FormatBool(row.TotalInfinite),
+		}); err != nil {
+			return err
+		}
+	default:
+		data, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		prefix, suffix := "", "\n"
+		if lw.format == ExportJSON {
+			prefix, suffix = ",\n", ""
+			if lw.n == 0 {
+				prefix = "[\n"
+			}
+		}
+		if _, err := io.WriteString(lw.w, prefix+string(data)+suffix); err != nil {
+			return err
+		}
+	}
+	lw.n++
+	return nil
+}
+
+// Close finishes the output: it writes the CSV header if no entries were
+// written, or the end of the JSON array, and flushes anything buffered. It
+// does not close the underlying writer.
+func (lw *LeaderboardWriter) Close() error {
+	switch lw.format {
+	case ExportCSV:
+		if lw.n == 0 {
+			lw.csv.Write(exportHeader)
+		}
+		lw.csv.Flush()
+		return lw.csv.Error()
+	case ExportJSON:
+		end := "\n]\n"
+		if lw.n == 0 {
+			end = "[]\n"
+		}
+		_, err := io.WriteString(lw.w, end)
+		return err
+	}
+	return nil
+}
+
+// Count returns the number of entries written.
+func (lw *LeaderboardWriter) Count() int {
+	return lw.n
+}
+
+// ExportLeaderboard writes a guild's whole leaderboard to w, fetching it a
+// page at a time so that guilds of any size are exported in constant
+// memory, and returns the number of entries written:
+//
+//	f, _ := os.Create("economy.csv")
+//	defer f.Close()
+//	n, err := v1.ExportLeaderboard(ctx, api, guildID, f, v1.ExportOptions{Format: v1.ExportCSV})
+//
+// If fetching a page fails the output is left incomplete.
+func ExportLeaderboard(ctx context.Context, api API, guild string, w io.Writer, opts ExportOptions) (int, error) {
+	if opts.Format == "" {
+		opts.Format = ExportCSV
+	}
+	if opts.PageSize <= 0 {
+		opts.PageSize = DefaultExportPageSize
+	}
+	lw, err := NewLeaderboardWriter(w, opts.Format)
+	if err != nil {
+		return 0, err
+	}
+	it := api.IterateLeaderboardContext(ctx, guild, LeaderboardOptions{Sort: opts.Sort, Limit: opts.PageSize})
+	for it.Next() {
+		if err := lw.Write(it.Value()); err != nil {
+			return lw.Count(), err
+		}
+	}
+	if err := it.Err(); err != nil {
+		if lw.csv != nil {
+			lw.csv.Flush()
+		}
+		return lw.Count(), err
+	}
+	return lw.Count(), lw.Close()
+}
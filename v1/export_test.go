@@ -0,0 +1,102 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExportLeaderboardCSV(t *testing.T) {
+	var calls int32
+	server := newLeaderboardServer(t, 5, true, &calls)
+	defer server.Close()
+	api, err := NewClient("token", WithBaseURL(server.URL))
+	ok(t, err)
+
+	var buf bytes.Buffer
+	n, err := ExportLeaderboard(context.Background(), api, "411898639737421824", &buf, ExportOptions{PageSize: 2})
+	ok(t, err)
+	equals(t, 5, n)
+	equals(t, int32(3), calls)
+	equals(t, "rank,user_id,cash,bank,total,cash_infinite,bank_infinite,total_infinite\n"+
+		"1,1,1,0,1,false,false,false\n"+
+		"2,2,2,0,2,false,false,false\n"+
+		"3,3,3,0,3,false,false,false\n"+
+		"4,4,4,0,4,false,false,false\n"+
+		"5,5,5,0,5,false,false,false\n", buf.String())
+}
+
+func TestExportLeaderboardJSONAndNDJSON(t *testing.T) {
+	var calls int32
+	server := newLeaderboardServer(t, 3, true, &calls)
+	defer server.Close()
+	api, err := NewClient("token", WithBaseURL(server.URL))
+	ok(t, err)
+
+	var buf bytes.Buffer
+	_, err = ExportLeaderboard(context.Background(), api, "411898639737421824", &buf, ExportOptions{Format: ExportJSON, PageSize: 2})
+	ok(t, err)
+	var rows []map[string]interface{}
+	ok(t, json.Unmarshal(buf.Bytes(), &rows))
+	equals(t, 3, len(rows))
+	equals(t, "2", rows[1]["user_id"])
+	equals(t, float64(2), rows[1]["cash"])
+	equals(t, false, rows[1]["cash_infinite"])
+
+	buf.Reset()
+	_, err = ExportLeaderboard(context.Background(), api, "411898639737421824", &buf, ExportOptions{Format: ExportNDJSON, PageSize: 2})
+	ok(t, err)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	equals(t, 3, len(lines))
+	var row map[string]interface{}
+	ok(t, json.Unmarshal([]byte(lines[2]), &row))
+	equals(t, float64(3), row["rank"])
+}
+
+func TestExportLeaderboardEmpty(t *testing.T) {
+	var calls int32
+	server := newLeaderboardServer(t, 0, true, &calls)
+	defer server.Close()
+	api, err := NewClient("token", WithBaseURL(server.URL))
+	ok(t, err)
+
+	for format, want := range map[ExportFormat]string{
+		ExportCSV:    "rank,user_id,cash,bank,total,cash_infinite,bank_infinite,total_infinite\n",
+		ExportJSON:   "[]\n",
+		ExportNDJSON: "",
+	} {
+		var buf bytes.Buffer
+		n, err := ExportLeaderboard(context.Background(), api, "411898639737421824", &buf, ExportOptions{Format: format})
+		ok(t, err)
+		equals(t, 0, n)
+		equals(t, want, buf.String())
+	}
+}
+
+func TestExportLeaderboardStopsOnError(t *testing.T) {
+	var calls int32
+	api := Custom("token", setSequenceClient(&calls,
+		cannedResponse{200, `{"users":[{"rank":"1","user_id":"1","cash":1,"bank":0,"total":1},{"rank":"2","user_id":"2","cash":1,"bank":0,"total":1}],"total_pages":3}`},
+		cannedResponse{500, ``},
+	))
+
+	var buf bytes.Buffer
+	n, err := ExportLeaderboard(context.Background(), api, "411898639737421824", &buf, ExportOptions{PageSize: 2})
+	assert(t, err != nil, "expected an error")
+	equals(t, 2, n)
+	equals(t, 3, strings.Count(buf.String(), "\n"))
+
+	_, err = ExportLeaderboard(context.Background(), api, "411898639737421824", &buf, ExportOptions{Format: "xlsx"})
+	assert(t, err != nil && strings.Contains(err.Error(), "unknown export format"), "expected an unknown format error, got %v", err)
+}
+
+func TestLeaderboardWriterFlagsInfinity(t *testing.T) {
+	var buf bytes.Buffer
+	lw, err := NewLeaderboardWriter(&buf, ExportCSV)
+	ok(t, err)
+	ok(t, lw.Write(LeaderboardEntry{Balance{Rank: 1, UserID: "1", Cash: Inf(1), Bank: NewAmount(5), Total: Inf(1)}}))
+	ok(t, lw.Close())
+	equals(t, "1,1,Infinity,5,Infinity,true,false,true", strings.Split(buf.String(), "\n")[1])
+}
@@ -0,0 +1,57 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Guild is a Discord server using UnbelievaBoat, as returned by GetGuild.
+type Guild struct {
+	// ID is the Discord ID of the guild.
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Icon is the hash of the guild's icon, or empty if it has none.
+	Icon        string `json:"icon"`
+	OwnerID     string `json:"owner_id"`
+	MemberCount int    `json:"member_count"`
+	// Symbol is the guild's currency symbol, which may be a custom emoji
+	// such as "<:coin:123456789012345678>".
+	Symbol string `json:"symbol"`
+}
+
+// IconURL returns the URL of the guild's icon on the Discord CDN, or "" if
+// it has none.
+func (g Guild) IconURL() string {
+	if g.Icon == "" {
+		return ""
+	}
+	ext := "png"
+	if strings.HasPrefix(g.Icon, "a_") {
+		ext = "gif"
+	}
+	return fmt.Sprintf("https://cdn.discordapp.com/icons/%v/%v.%v", g.ID, g.Icon, ext)
+}
+
+// FormatAmount formats a with the guild's currency symbol.
+func (g Guild) FormatAmount(a Amount) string {
+	return a.FormatCurrency(g.Symbol)
+}
+
+// GetGuild fetches a guild's details and currency symbol.
+func (u *Client) GetGuild(guild string) (Guild, error) {
+	return u.GetGuildContext(context.Background(), guild)
+}
+
+// GetGuildContext is like GetGuild but bound to ctx.
+func (u *Client) GetGuildContext(ctx context.Context, guild string) (Guild, error) {
+	op := &Operation{Name: "GetGuild", Method: "GET", Path: fmt.Sprintf("/guilds/%v", guild), GuildID: guild}
+	return call(ctx, u, op, func(data []byte) (Guild, error) {
+		var g Guild
+		if err := json.Unmarshal(data, &g); err != nil {
+			return Guild{}, err
+		}
+		return g, nil
+	})
+}
@@ -0,0 +1,39 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+const testGuild = `{"id":"411898639737421824","name":"UnbelievaBoat","icon":"a_0123456789abcdef","owner_id":"116293018742554625","member_count":14210,"symbol":"£"}`
+
+func TestGetGuildHandlesDataOnSuccessfulFetchCorrectly(t *testing.T) {
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		equals(t, "https://unbelievable.pizza/api/v1/guilds/411898639737421824", req.URL.String())
+		return setClient(200, "", testGuild).Transport.(RoundTripFunc)(req)
+	})
+
+	api := Custom("token", client)
+	guild, err := api.GetGuild("411898639737421824")
+	ok(t, err)
+	equals(t, Guild{"411898639737421824", "UnbelievaBoat", "a_0123456789abcdef", "116293018742554625", 14210, "£"}, guild)
+	equals(t, "https://cdn.discordapp.com/icons/411898639737421824/a_0123456789abcdef.gif", guild.IconURL())
+	equals(t, "£1,234", guild.FormatAmount(NewAmount(1234)))
+}
+
+func TestGuildIconURLIsEmptyWithoutIcon(t *testing.T) {
+	equals(t, "", Guild{ID: "411898639737421824"}.IconURL())
+	equals(t, "https://cdn.discordapp.com/icons/411898639737421824/0123.png", Guild{ID: "411898639737421824", Icon: "0123"}.IconURL())
+}
+
+func TestGetGuildHandlesErrorOnUnsuccessfulFetchCorrectly(t *testing.T) {
+	client := setClient(404, "/guilds/000000000000000000", `{"error":"404: Not found","message":"Unknown guild"}`)
+
+	api := Custom("token", client)
+	guild, err := api.GetGuild("000000000000000000")
+	equals(t, Guild{}, guild)
+	var apiErr *APIError
+	assert(t, errors.As(err, &apiErr), "expected *APIError, got %#v", err)
+	equals(t, 404, apiErr.StatusCode)
+}
@@ -0,0 +1,328 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrUnreconciled is returned for a keyed update whose earlier attempt failed
+// ambiguously when re-reading the balance cannot tell whether it was applied,
+// because the balance has since been changed by something else. The update
+// is not sent again; check the balance by hand.
+var ErrUnreconciled = errors.New("v1: cannot tell whether an earlier attempt was applied")
+
+// ErrInFlight is returned for a keyed update while another update with the
+// same key, in this process or another sharing the store, is being sent.
+// Try again once it has finished: the update will then be found applied, or
+// be reconciled and resent.
+var ErrInFlight = errors.New("v1: an update with this idempotency key is in flight")
+
+// idempotencyLease is how long a record stays in flight without being
+// saved, after which it is assumed to have been abandoned by a call that
+// crashed and may be taken over.
+const idempotencyLease = 2 * time.Minute
+
+// IdempotencyRecord is what an IdempotencyStore keeps for a keyed balance
+// update.
+type IdempotencyRecord struct {
+	Guild string `json:"guild"`
+	User  string `json:"user"`
+	// Version is bumped by every write of the record, for CompareAndSwap.
+	// Stored records have a version of at least 1.
+	Version int `json:"version"`
+	// Pending is true while a call is working on the update, from before
+	// its balance is read until it is applied or given up on. PendingSince
+	// is when the record was last saved by that call.
+	Pending      bool      `json:"pending"`
+	PendingSince time.Time `json:"pending_since,omitempty"`
+	// Sent is true once an attempt may have been sent.
+	Sent bool `json:"sent"`
+	// Done is true once the update is known to have been applied.
+	Done bool `json:"done"`
+	// Before is the balance read before the last attempt was sent. It is
+	// only recorded for updates that add to the balance.
+	Before *Balance `json:"before,omitempty"`
+	// Result is the balance after the update, once Done.
+	Result    Balance   `json:"result"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IdempotencyStore remembers keyed balance updates, so that a retry of one
+// that has already been applied is suppressed. The client uses an in-memory
+// store unless WithIdempotencyStore is used; keep records in a shared store
+// such as Redis to deduplicate across restarts or processes.
+//
+// A call reserves its key by swapping in a pending record before it reads
+// the balance or sends anything, and other calls with the same key fail
+// with ErrInFlight until it finishes. The reservation is only as good as
+// CompareAndSwap: for updates to be applied once across processes, it must
+// be atomic across them, such as a Redis transaction or a conditional write.
+//
+// When a keyed update fails ambiguously (a server error, network failure or
+// timeout, after which the API may or may not have applied it), its record
+// is left unfinished. The next attempt with the same key re-reads the
+// balance first: if the update shows as applied it is not sent again, if the
+// balance is untouched it is resent, and otherwise ErrUnreconciled is
+// returned.
+//
+// Implementations must be safe for concurrent use.
+type IdempotencyStore interface {
+	// Get returns the record for key, and whether there is one.
+	Get(ctx context.Context, key string) (IdempotencyRecord, bool, error)
+	// CompareAndSwap saves rec under key if the record there has version
+	// old, or if there is none and old is 0, and reports whether it did.
+	CompareAndSwap(ctx context.Context, key string, old int, rec IdempotencyRecord) (bool, error)
+	// Delete forgets key.
+	Delete(ctx context.Context, key string) error
+}
+
+// WithIdempotencyStore sets where keyed balance updates are remembered.
+func WithIdempotencyStore(store IdempotencyStore) Option {
+	return func(c *Client) error {
+		if store == nil {
+			return errors.New("v1: nil IdempotencyStore")
+		}
+		c.idempotency = store
+		return nil
+	}
+}
+
+// MemoryIdempotencyStore is an IdempotencyStore that keeps records in memory
+// for a fixed time.
+type MemoryIdempotencyStore struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	records map[string]IdempotencyRecord
+}
+
+// NewMemoryIdempotencyStore returns a store that forgets records ttl after
+// they were created. Zero means never.
+func NewMemoryIdempotencyStore(ttl time.Duration) *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{ttl: ttl, records: make(map[string]IdempotencyRecord)}
+}
+
+// Get implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Get(ctx context.Context, key string) (IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.get(key)
+	return rec, ok, nil
+}
+
+// CompareAndSwap implements IdempotencyStore. It also drops expired records.
+func (s *MemoryIdempotencyStore) CompareAndSwap(ctx context.Context, key string, old int, rec IdempotencyRecord) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ttl > 0 {
+		for k, r := range s.records {
+			if time.Since(r.CreatedAt) > s.ttl {
+				delete(s.records, k)
+			}
+		}
+	}
+	if cur, _ := s.get(key); cur.Version != old {
+		return false, nil
+	}
+	s.records[key] = rec
+	return true, nil
+}
+
+// get returns the record for key unless it has expired. s.mu must be held.
+func (s *MemoryIdempotencyStore) get(key string) (IdempotencyRecord, bool) {
+	rec, ok := s.records[key]
+	if ok && s.ttl > 0 && time.Since(rec.CreatedAt) > s.ttl {
+		delete(s.records, key)
+		return IdempotencyRecord{}, false
+	}
+	return rec, ok
+}
+
+// Delete implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+// modifyOnce applies a keyed update at most once. Ambiguous failures are
+// retried as the retry policy allows, reconciling before each resend.
+func (u *Client) modifyOnce(ctx context.Context, op *Operation, update *BalanceUpdate) (Balance, error) {
+	// The timeout bounds the whole call, reconciling and retries included,
+	// rather than each request within it.
+	if u.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, u.timeout)
+		defer cancel()
+	}
+	key := update.key
+	guild, user := op.GuildID, op.UserID
+	// Each send is a single attempt (429s aside), as the loop below owns the
+	// retry budget and must reconcile before every resend.
+	op.oneShot = true
+	rec, err := u.claim(ctx, key, guild, user)
+	if err != nil {
+		return Balance{}, err
+	}
+	if rec.Done {
+		return rec.Result, nil
+	}
+	for attempt := 0; ; attempt++ {
+		current, err := u.reconcile(ctx, guild, user, update, &rec)
+		if err != nil {
+			return Balance{}, u.release(ctx, key, rec, err)
+		}
+		if rec.Done {
+			rec.Pending = false
+			return rec.Result, u.save(ctx, key, &rec)
+		}
+		rec.Before, rec.Sent = current, true
+		if err := u.save(ctx, key, &rec); err != nil {
+			return Balance{}, err
+		}
+
+		// Waits for the rate limiter or a retry-after must not outlast the
+		// lease, or another call could take the key over and send the
+		// update again. Half of it is left for a request already sent.
+		sctx, cancel := context.WithDeadline(ctx, rec.PendingSince.Add(idempotencyLease/2))
+		bal, err := u.sendUpdate(sctx, op)
+		cancel()
+		if err == nil {
+			rec.Pending, rec.Done, rec.Result = false, true, bal
+			return bal, u.save(ctx, key, &rec)
+		}
+		if !ambiguous(err) {
+			// Never applied, so a later attempt may send it afresh.
+			if delErr := u.idempotency.Delete(ctx, key); delErr != nil {
+				return Balance{}, delErr
+			}
+			return Balance{}, err
+		}
+		if retry, waitErr := u.backOff(ctx, attempt, true, op.Method, op.Path, err); !retry || waitErr != nil {
+			return Balance{}, u.release(ctx, key, rec, err)
+		}
+	}
+}
+
+// claim reserves key for this call by saving a pending record, returning
+// it. A done record is returned as it is.
+func (u *Client) claim(ctx context.Context, key, guild, user string) (IdempotencyRecord, error) {
+	for try := 0; try < 3; try++ {
+		rec, found, err := u.idempotency.Get(ctx, key)
+		if err != nil {
+			return IdempotencyRecord{}, err
+		}
+		switch {
+		case found && (rec.Guild != guild || rec.User != user):
+			return IdempotencyRecord{}, fmt.Errorf("%w: idempotency key %q was used for another user", ErrInvalidUpdate, key)
+		case rec.Done:
+			return rec, nil
+		case rec.Pending && time.Since(rec.PendingSince) < idempotencyLease:
+			return IdempotencyRecord{}, fmt.Errorf("%w (key %q)", ErrInFlight, key)
+		case !found:
+			rec = IdempotencyRecord{Guild: guild, User: user, CreatedAt: time.Now()}
+		}
+		next := rec
+		next.Version++
+		next.Pending, next.PendingSince = true, time.Now()
+		ok, err := u.idempotency.CompareAndSwap(ctx, key, rec.Version, next)
+		if err != nil {
+			return IdempotencyRecord{}, err
+		}
+		if ok {
+			return next, nil
+		}
+		// Another call got there first; look again.
+	}
+	return IdempotencyRecord{}, fmt.Errorf("%w (key %q)", ErrInFlight, key)
+}
+
+// save writes rec, which this call has claimed, renewing its lease.
+func (u *Client) save(ctx context.Context, key string, rec *IdempotencyRecord) error {
+	next := *rec
+	next.Version++
+	if next.Pending {
+		next.PendingSince = time.Now()
+	}
+	ok, err := u.idempotency.CompareAndSwap(ctx, key, rec.Version, next)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// Our lease ran out and another call took over.
+		return fmt.Errorf("%w (key %q)", ErrInFlight, key)
+	}
+	*rec = next
+	return nil
+}
+
+// release gives up this call's claim on key after it failed with err,
+// leaving the record for a later attempt to reconcile, and returns err.
+func (u *Client) release(ctx context.Context, key string, rec IdempotencyRecord, err error) error {
+	if !rec.Sent {
+		// Nothing was sent, so there is nothing to remember.
+		u.idempotency.Delete(ctx, key)
+		return err
+	}
+	rec.Pending = false
+	u.save(ctx, key, &rec)
+	return err
+}
+
+// reconcile works out, before update is sent, whether an earlier attempt
+// recorded in rec was already applied, marking rec done if so. For updates
+// that add to the balance it returns the current balance to record as
+// Before.
+func (u *Client) reconcile(ctx context.Context, guild, user string, update *BalanceUpdate, rec *IdempotencyRecord) (*Balance, error) {
+	if !rec.Sent && !update.add {
+		// Setting is idempotent, so there is nothing to compare.
+		return nil, nil
+	}
+	current, err := u.GetBalanceContext(ctx, guild, user)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == 404 {
+		// Users without a balance yet have nothing.
+		current, err = Balance{UserID: user}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !rec.Sent {
+		return &current, nil
+	}
+	switch {
+	case !update.add:
+		if sameBalance(update.Apply(current), current) {
+			rec.Done, rec.Result = true, current
+		}
+	case rec.Before == nil:
+		// The record was written by a set update under the same key.
+		return nil, fmt.Errorf("%w: idempotency key %q was used for another update", ErrInvalidUpdate, update.key)
+	case sameBalance(update.Apply(*rec.Before), current):
+		rec.Done, rec.Result = true, current
+	case !sameBalance(*rec.Before, current):
+		return nil, fmt.Errorf("%w (key %q)", ErrUnreconciled, update.key)
+	}
+	return &current, nil
+}
+
+func sameBalance(a, b Balance) bool {
+	return a.Cash.Cmp(b.Cash) == 0 && a.Bank.Cmp(b.Bank) == 0
+}
+
+// ambiguous reports whether a request that failed with err may still have
+// been applied by the API.
+func ambiguous(err error) bool {
+	var rateErr *RateLimitError
+	var apiErr *APIError
+	switch {
+	case errors.As(err, &rateErr):
+		return false
+	case errors.As(err, &apiErr):
+		return apiErr.StatusCode >= 500
+	}
+	return true
+}
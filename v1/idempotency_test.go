@@ -0,0 +1,303 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// balanceServer keeps one user's cash and applies PUTs and PATCHes to it.
+// When applyThenFail is set, the next update is applied but answered with a
+// 502, as if the response was lost.
+type balanceServer struct {
+	mu            sync.Mutex
+	cash          int64
+	updates       int
+	reads         int
+	applyThenFail bool
+	reject        bool
+	delay         time.Duration
+}
+
+func (s *balanceServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	time.Sleep(s.delay)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.Method == http.MethodGet {
+		s.reads++
+	} else {
+		s.updates++
+		if s.reject {
+			w.WriteHeader(400)
+			w.Write([]byte(`{"error":"400: Bad Request"}`))
+			return
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		var change struct{ Cash int64 }
+		json.Unmarshal(b, &change)
+		if r.Method == http.MethodPatch {
+			s.cash += change.Cash
+		} else {
+			s.cash = change.Cash
+		}
+		if s.applyThenFail {
+			s.applyThenFail = false
+			w.WriteHeader(502)
+			return
+		}
+	}
+	fmt.Fprintf(w, `{"user_id":"398197113495748626","cash":%d,"bank":0,"total":%d}`, s.cash, s.cash)
+}
+
+func newIdempotencyClient(t *testing.T, s *balanceServer, opts ...Option) *Client {
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	api, err := NewClient("token", append([]Option{WithBaseURL(server.URL)}, opts...)...)
+	ok(t, err)
+	return api
+}
+
+func TestIdempotencyKeySuppressesRepeats(t *testing.T) {
+	s := &balanceServer{cash: 10}
+	api := newIdempotencyClient(t, s)
+
+	for i := 0; i < 3; i++ {
+		bal, err := api.ModifyBalance("411898639737421824", "398197113495748626", NewBalanceUpdate().AddCash(NewAmount(5)).IdempotencyKey("payday"))
+		ok(t, err)
+		equals(t, NewAmount(15), bal.Cash)
+	}
+	equals(t, int64(15), s.cash)
+	equals(t, 1, s.updates)
+}
+
+func TestIdempotencyKeyReconcilesAmbiguousFailure(t *testing.T) {
+	s := &balanceServer{cash: 10, applyThenFail: true}
+	api := newIdempotencyClient(t, s)
+	update := func() *BalanceUpdate {
+		return NewBalanceUpdate().AddCash(NewAmount(5)).IdempotencyKey("payday")
+	}
+
+	_, err := api.ModifyBalance("411898639737421824", "398197113495748626", update())
+	var apiErr *APIError
+	assert(t, errors.As(err, &apiErr), "expected *APIError, got %#v", err)
+
+	// The server applied it, so the retry only re-reads the balance.
+	bal, err := api.ModifyBalance("411898639737421824", "398197113495748626", update())
+	ok(t, err)
+	equals(t, NewAmount(15), bal.Cash)
+	equals(t, int64(15), s.cash)
+	equals(t, 1, s.updates)
+}
+
+func TestIdempotencyKeyRetriesAmbiguousFailureWithPolicy(t *testing.T) {
+	s := &balanceServer{cash: 10, applyThenFail: true}
+	api := newIdempotencyClient(t, s, WithRetryPolicy(RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond}))
+
+	bal, err := api.ModifyBalance("411898639737421824", "398197113495748626", NewBalanceUpdate().AddCash(NewAmount(5)).IdempotencyKey("payday"))
+	ok(t, err)
+	equals(t, NewAmount(15), bal.Cash)
+	equals(t, 1, s.updates)
+}
+
+func TestIdempotencyKeySharesOneRetryBudget(t *testing.T) {
+	var puts int32
+	api, err := NewClient("token",
+		WithHTTPClient(NewTestClient(func(req *http.Request) *http.Response {
+			if req.Method == http.MethodGet {
+				return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(testBalance)), Header: make(http.Header)}
+			}
+			atomic.AddInt32(&puts, 1)
+			return &http.Response{StatusCode: 502, Body: ioutil.NopCloser(strings.NewReader(``)), Header: make(http.Header)}
+		})),
+		WithRetryPolicy(RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond}),
+	)
+	ok(t, err)
+
+	_, err = api.ModifyBalance("411898639737421824", "398197113495748626", NewBalanceUpdate().SetCash(NewAmount(5)).IdempotencyKey("reset"))
+	var apiErr *APIError
+	assert(t, errors.As(err, &apiErr), "expected *APIError, got %#v", err)
+	equals(t, int32(4), puts)
+}
+
+func TestIdempotencyKeyTimeoutBoundsWholeCall(t *testing.T) {
+	api, err := NewClient("token",
+		WithHTTPClient(NewTestClient(func(req *http.Request) *http.Response {
+			time.Sleep(20 * time.Millisecond)
+			if req.Method == http.MethodGet {
+				return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(testBalance)), Header: make(http.Header)}
+			}
+			return &http.Response{StatusCode: 502, Body: ioutil.NopCloser(strings.NewReader(``)), Header: make(http.Header)}
+		})),
+		WithRetryPolicy(RetryPolicy{MaxRetries: 50, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+		WithTimeout(100*time.Millisecond),
+	)
+	ok(t, err)
+
+	start := time.Now()
+	_, err = api.ModifyBalance("411898639737421824", "398197113495748626", NewBalanceUpdate().AddCash(NewAmount(5)).IdempotencyKey("payday"))
+	assert(t, err != nil, "expected an error")
+	assert(t, time.Since(start) < 500*time.Millisecond, "keyed update ran for %v despite a 100ms timeout", time.Since(start))
+}
+
+func TestIdempotencyKeyDoesNotWaitOutItsLease(t *testing.T) {
+	var calls int32
+	api, err := NewClient("token",
+		WithHTTPClient(setSequenceClient(&calls, cannedResponse{429, `{"message":"You are being rate limited.","retry_after":600000}`}, cannedResponse{200, testBalance})),
+		WithRetryPolicy(RetryPolicy{MaxRetries: 3}),
+	)
+	ok(t, err)
+
+	start := time.Now()
+	_, err = api.ModifyBalance("411898639737421824", "398197113495748626", NewBalanceUpdate().SetCash(NewAmount(5)).IdempotencyKey("reset"))
+	var rateErr *RateLimitError
+	assert(t, errors.As(err, &rateErr), "expected *RateLimitError, got %#v", err)
+	assert(t, time.Since(start) < time.Second, "keyed update waited %v for a retry-after longer than its lease", time.Since(start))
+	equals(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestIdempotencyKeyResendsUnappliedUpdate(t *testing.T) {
+	store := NewMemoryIdempotencyStore(time.Hour)
+	before := Balance{UserID: "398197113495748626", Cash: NewAmount(10), Total: NewAmount(10)}
+	swapped, err := store.CompareAndSwap(context.Background(), "payday", 0, IdempotencyRecord{
+		Guild: "411898639737421824", User: "398197113495748626", Version: 1, Sent: true, Before: &before, CreatedAt: time.Now(),
+	})
+	ok(t, err)
+	equals(t, true, swapped)
+	s := &balanceServer{cash: 10}
+	api := newIdempotencyClient(t, s, WithIdempotencyStore(store))
+
+	bal, err := api.ModifyBalance("411898639737421824", "398197113495748626", NewBalanceUpdate().AddCash(NewAmount(5)).IdempotencyKey("payday"))
+	ok(t, err)
+	equals(t, NewAmount(15), bal.Cash)
+	rec, found, err := store.Get(context.Background(), "payday")
+	ok(t, err)
+	equals(t, true, found && rec.Done)
+}
+
+func TestIdempotencyKeyReportsUnreconciledBalance(t *testing.T) {
+	store := NewMemoryIdempotencyStore(0)
+	before := Balance{UserID: "398197113495748626", Cash: NewAmount(10), Total: NewAmount(10)}
+	swapped, err := store.CompareAndSwap(context.Background(), "payday", 0, IdempotencyRecord{
+		Guild: "411898639737421824", User: "398197113495748626", Version: 1, Sent: true, Before: &before, CreatedAt: time.Now(),
+	})
+	ok(t, err)
+	equals(t, true, swapped)
+	s := &balanceServer{cash: 12}
+	api := newIdempotencyClient(t, s, WithIdempotencyStore(store))
+
+	_, err = api.ModifyBalance("411898639737421824", "398197113495748626", NewBalanceUpdate().AddCash(NewAmount(5)).IdempotencyKey("payday"))
+	assert(t, errors.Is(err, ErrUnreconciled), "expected ErrUnreconciled, got %v", err)
+	equals(t, 0, s.updates)
+
+	_, err = api.ModifyBalance("411898639737421824", "1", NewBalanceUpdate().AddCash(NewAmount(5)).IdempotencyKey("payday"))
+	assert(t, errors.Is(err, ErrInvalidUpdate), "expected ErrInvalidUpdate, got %v", err)
+}
+
+func TestIdempotencyKeyIsForgottenAfterRejection(t *testing.T) {
+	s := &balanceServer{reject: true}
+	api := newIdempotencyClient(t, s)
+	update := func() *BalanceUpdate {
+		return NewBalanceUpdate().SetCash(NewAmount(5)).IdempotencyKey("reset")
+	}
+
+	_, err := api.ModifyBalance("411898639737421824", "398197113495748626", update())
+	var apiErr *APIError
+	assert(t, errors.As(err, &apiErr), "expected *APIError, got %#v", err)
+
+	s.reject = false
+	_, err = api.ModifyBalance("411898639737421824", "398197113495748626", update())
+	ok(t, err)
+	equals(t, int64(5), s.cash)
+	equals(t, 2, s.updates)
+	equals(t, 0, s.reads)
+}
+
+func TestMemoryIdempotencyStoreExpiresRecords(t *testing.T) {
+	store := NewMemoryIdempotencyStore(time.Minute)
+	ctx := context.Background()
+	_, err := store.CompareAndSwap(ctx, "old", 0, IdempotencyRecord{Version: 1, CreatedAt: time.Now().Add(-time.Hour)})
+	ok(t, err)
+	_, err = store.CompareAndSwap(ctx, "new", 0, IdempotencyRecord{Version: 1, CreatedAt: time.Now()})
+	ok(t, err)
+
+	_, found, err := store.Get(ctx, "old")
+	ok(t, err)
+	equals(t, false, found)
+	_, found, err = store.Get(ctx, "new")
+	ok(t, err)
+	equals(t, true, found)
+	swapped, err := store.CompareAndSwap(ctx, "new", 0, IdempotencyRecord{Version: 1, CreatedAt: time.Now()})
+	ok(t, err)
+	equals(t, false, swapped)
+	swapped, err = store.CompareAndSwap(ctx, "new", 1, IdempotencyRecord{Version: 2, CreatedAt: time.Now()})
+	ok(t, err)
+	equals(t, true, swapped)
+	ok(t, store.Delete(ctx, "new"))
+	_, found, _ = store.Get(ctx, "new")
+	equals(t, false, found)
+}
+
+func TestIdempotencyKeyAppliesConcurrentCallsOnce(t *testing.T) {
+	s := &balanceServer{cash: 10, delay: 20 * time.Millisecond}
+	api := newIdempotencyClient(t, s)
+
+	errs := make([]error, 5)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = api.ModifyBalance("411898639737421824", "398197113495748626", NewBalanceUpdate().AddCash(NewAmount(100)).IdempotencyKey("payday"))
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		assert(t, err == nil || errors.Is(err, ErrInFlight), "expected success or ErrInFlight, got %v", err)
+	}
+	equals(t, int64(110), s.cash)
+	equals(t, 1, s.updates)
+
+	// Once the first call has finished, the key reports its result.
+	bal, err := api.ModifyBalance("411898639737421824", "398197113495748626", NewBalanceUpdate().AddCash(NewAmount(100)).IdempotencyKey("payday"))
+	ok(t, err)
+	equals(t, NewAmount(110), bal.Cash)
+}
+
+func TestIdempotencyKeyTakesOverAbandonedRecord(t *testing.T) {
+	store := NewMemoryIdempotencyStore(0)
+	before := Balance{UserID: "398197113495748626", Cash: NewAmount(10), Total: NewAmount(10)}
+	rec := IdempotencyRecord{
+		Guild: "411898639737421824", User: "398197113495748626", Version: 1,
+		Pending: true, PendingSince: time.Now(), Sent: true, Before: &before, CreatedAt: time.Now(),
+	}
+	_, err := store.CompareAndSwap(context.Background(), "payday", 0, rec)
+	ok(t, err)
+	s := &balanceServer{cash: 10}
+	api := newIdempotencyClient(t, s, WithIdempotencyStore(store))
+	update := func() *BalanceUpdate {
+		return NewBalanceUpdate().AddCash(NewAmount(5)).IdempotencyKey("payday")
+	}
+
+	_, err = api.ModifyBalance("411898639737421824", "398197113495748626", update())
+	assert(t, errors.Is(err, ErrInFlight), "expected ErrInFlight, got %v", err)
+	equals(t, 0, s.reads+s.updates)
+
+	// A call that has gone quiet for longer than the lease has crashed.
+	old := rec
+	rec.Version, rec.PendingSince = 2, time.Now().Add(-time.Hour)
+	_, err = store.CompareAndSwap(context.Background(), "payday", old.Version, rec)
+	ok(t, err)
+	bal, err := api.ModifyBalance("411898639737421824", "398197113495748626", update())
+	ok(t, err)
+	equals(t, NewAmount(15), bal.Cash)
+	equals(t, 1, s.updates)
+}
@@ -0,0 +1,148 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ErrInvalidQuantity is returned when adding or removing less than one of
+// an item.
+var ErrInvalidQuantity = errors.New("v1: quantity must be at least 1")
+
+// InventoryItem is a stack of one item in a user's inventory.
+type InventoryItem struct {
+	// ItemID is the ID of the store item.
+	ItemID   string `json:"item_id"`
+	Name     string `json:"name"`
+	Emoji    string `json:"emoji,omitempty"`
+	Quantity int    `json:"quantity"`
+}
+
+// InventoryPage is one page of a user's inventory.
+type InventoryPage struct {
+	Items []InventoryItem
+	// TotalPages is the number of pages in the inventory, or 0 when the API
+	// didn't say.
+	TotalPages int
+}
+
+type inventoryChange struct {
+	ItemID   string `json:"item_id,omitempty"`
+	Quantity int    `json:"quantity"`
+}
+
+func inventoryPath(guild, user string) string {
+	return fmt.Sprintf("/guilds/%v/users/%v/inventory", guild, user)
+}
+
+func inventoryItemPath(guild, user, item string) string {
+	return fmt.Sprintf("/guilds/%v/users/%v/inventory/%v", guild, user, url.PathEscape(item))
+}
+
+// ListInventory fetches a page of a user's inventory in a guild.
+func (u *Client) ListInventory(guild, user string, opts ListOptions) (InventoryPage, error) {
+	return u.ListInventoryContext(context.Background(), guild, user, opts)
+}
+
+// ListInventoryContext is like ListInventory but bound to ctx.
+func (u *Client) ListInventoryContext(ctx context.Context, guild, user string, opts ListOptions) (InventoryPage, error) {
+	query, err := opts.query()
+	if err != nil {
+		return InventoryPage{}, err
+	}
+	op := &Operation{Name: "ListInventory", Method: "GET", Path: inventoryPath(guild, user) + query, GuildID: guild, UserID: user}
+	return call(ctx, u, op, func(data []byte) (InventoryPage, error) {
+		items, totalPages, err := decodePage[InventoryItem](data, "items")
+		if err != nil {
+			return InventoryPage{}, err
+		}
+		return InventoryPage{Items: items, TotalPages: totalPages}, nil
+	})
+}
+
+// IterateInventory returns an iterator over a user's whole inventory,
+// starting at opts.Page and fetching pages of opts.Limit items as needed.
+func (u *Client) IterateInventory(guild, user string, opts ListOptions) *Iterator[InventoryItem] {
+	return u.IterateInventoryContext(context.Background(), guild, user, opts)
+}
+
+// IterateInventoryContext is like IterateInventory but every page is
+// fetched with ctx.
+func (u *Client) IterateInventoryContext(ctx context.Context, guild, user string, opts ListOptions) *Iterator[InventoryItem] {
+	return NewIterator(ctx, opts, func(ctx context.Context, opts ListOptions) ([]InventoryItem, int, error) {
+		page, err := u.ListInventoryContext(ctx, guild, user, opts)
+		return page.Items, page.TotalPages, err
+	})
+}
+
+// GetInventoryItem fetches how many of an item a user has.
+func (u *Client) GetInventoryItem(guild, user, item string) (InventoryItem, error) {
+	return u.GetInventoryItemContext(context.Background(), guild, user, item)
+}
+
+// GetInventoryItemContext is like GetInventoryItem but bound to ctx.
+func (u *Client) GetInventoryItemContext(ctx context.Context, guild, user, item string) (InventoryItem, error) {
+	op := &Operation{Name: "GetInventoryItem", Method: "GET", Path: inventoryItemPath(guild, user, item), GuildID: guild, UserID: user}
+	return call(ctx, u, op, decodeInventoryItem)
+}
+
+// AddInventoryItem gives a user quantity of an item and returns their new
+// stack of it.
+func (u *Client) AddInventoryItem(guild, user, item string, quantity int) (InventoryItem, error) {
+	return u.AddInventoryItemContext(context.Background(), guild, user, item, quantity)
+}
+
+// AddInventoryItemContext is like AddInventoryItem but bound to ctx.
+func (u *Client) AddInventoryItemContext(ctx context.Context, guild, user, item string, quantity int) (InventoryItem, error) {
+	if quantity < 1 {
+		return InventoryItem{}, ErrInvalidQuantity
+	}
+	value, err := json.Marshal(inventoryChange{ItemID: item, Quantity: quantity})
+	if err != nil {
+		return InventoryItem{}, err
+	}
+	op := &Operation{Name: "AddInventoryItem", Method: http.MethodPost, Path: inventoryPath(guild, user), GuildID: guild, UserID: user, Payload: value}
+	return call(ctx, u, op, decodeInventoryItem)
+}
+
+// RemoveInventoryItem takes quantity of an item from a user and returns
+// their remaining stack of it, which has a Quantity of 0 once it is gone.
+func (u *Client) RemoveInventoryItem(guild, user, item string, quantity int) (InventoryItem, error) {
+	return u.RemoveInventoryItemContext(context.Background(), guild, user, item, quantity)
+}
+
+// RemoveInventoryItemContext is like RemoveInventoryItem but bound to ctx.
+func (u *Client) RemoveInventoryItemContext(ctx context.Context, guild, user, item string, quantity int) (InventoryItem, error) {
+	if quantity < 1 {
+		return InventoryItem{}, ErrInvalidQuantity
+	}
+	value, err := json.Marshal(inventoryChange{Quantity: quantity})
+	if err != nil {
+		return InventoryItem{}, err
+	}
+	op := &Operation{
+		Name: "RemoveInventoryItem", Method: http.MethodDelete, Path: inventoryItemPath(guild, user, item),
+		GuildID: guild, UserID: user, Payload: value,
+		// Unlike most DELETEs this one isn't idempotent: repeating it
+		// removes more items.
+		oneShot: true,
+	}
+	return call(ctx, u, op, func(data []byte) (InventoryItem, error) {
+		if len(data) == 0 {
+			return InventoryItem{ItemID: item}, nil
+		}
+		return decodeInventoryItem(data)
+	})
+}
+
+func decodeInventoryItem(data []byte) (InventoryItem, error) {
+	var item InventoryItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		return InventoryItem{}, err
+	}
+	return item, nil
+}
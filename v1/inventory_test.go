@@ -0,0 +1,106 @@
+package v1
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+const testInventoryItem = `{"item_id":"1","name":"Pizza","emoji":"🍕","quantity":3}`
+
+func TestListInventoryHandlesDataOnSuccessfulFetchCorrectly(t *testing.T) {
+	var method, path, payload string
+	server := newItemServer(t, 200, `{"items":[`+testInventoryItem+`],"total_pages":1}`, &method, &path, &payload)
+	defer server.Close()
+	api, err := NewClient("token", WithBaseURL(server.URL))
+	ok(t, err)
+
+	page, err := api.ListInventory("411898639737421824", "398197113495748626", ListOptions{Limit: 10})
+	ok(t, err)
+	equals(t, "/guilds/411898639737421824/users/398197113495748626/inventory?limit=10", path)
+	equals(t, InventoryPage{Items: []InventoryItem{{"1", "Pizza", "🍕", 3}}, TotalPages: 1}, page)
+}
+
+func TestIterateInventoryStopsAfterShortPage(t *testing.T) {
+	var method, path, payload string
+	server := newItemServer(t, 200, `[`+testInventoryItem+`]`, &method, &path, &payload)
+	defer server.Close()
+	api, err := NewClient("token", WithBaseURL(server.URL))
+	ok(t, err)
+
+	// A page shorter than the limit is the last one.
+	it := api.IterateInventory("411898639737421824", "398197113495748626", ListOptions{Limit: 2})
+	var items []InventoryItem
+	for it.Next() {
+		items = append(items, it.Value())
+	}
+	ok(t, it.Err())
+	equals(t, 1, len(items))
+	equals(t, 1, it.Pages())
+}
+
+func TestGetInventoryItemHandlesDataOnSuccessfulFetchCorrectly(t *testing.T) {
+	var method, path, payload string
+	server := newItemServer(t, 200, testInventoryItem, &method, &path, &payload)
+	defer server.Close()
+	api, err := NewClient("token", WithBaseURL(server.URL))
+	ok(t, err)
+
+	item, err := api.GetInventoryItem("411898639737421824", "398197113495748626", "1")
+	ok(t, err)
+	equals(t, "GET", method)
+	equals(t, "/guilds/411898639737421824/users/398197113495748626/inventory/1", path)
+	equals(t, InventoryItem{"1", "Pizza", "🍕", 3}, item)
+}
+
+func TestAddInventoryItemPostsQuantity(t *testing.T) {
+	var method, path, payload string
+	server := newItemServer(t, 200, testInventoryItem, &method, &path, &payload)
+	defer server.Close()
+	api, err := NewClient("token", WithBaseURL(server.URL))
+	ok(t, err)
+
+	item, err := api.AddInventoryItem("411898639737421824", "398197113495748626", "1", 2)
+	ok(t, err)
+	equals(t, 3, item.Quantity)
+	equals(t, "POST", method)
+	equals(t, "/guilds/411898639737421824/users/398197113495748626/inventory", path)
+	equals(t, `{"item_id":"1","quantity":2}`, payload)
+}
+
+func TestRemoveInventoryItemDeletesQuantity(t *testing.T) {
+	var method, path, payload string
+	server := newItemServer(t, 200, `{"item_id":"1","name":"Pizza","quantity":1}`, &method, &path, &payload)
+	defer server.Close()
+	api, err := NewClient("token", WithBaseURL(server.URL))
+	ok(t, err)
+
+	item, err := api.RemoveInventoryItem("411898639737421824", "398197113495748626", "1", 2)
+	ok(t, err)
+	equals(t, 1, item.Quantity)
+	equals(t, "DELETE", method)
+	equals(t, "/guilds/411898639737421824/users/398197113495748626/inventory/1", path)
+	equals(t, `{"quantity":2}`, payload)
+}
+
+func TestRemoveInventoryItemIsNotRetriedOnServerError(t *testing.T) {
+	var calls int32
+	api, err := NewClient("token",
+		WithHTTPClient(setSequenceClient(&calls, cannedResponse{502, ``}, cannedResponse{200, testInventoryItem})),
+		WithRetryPolicy(RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond}),
+	)
+	ok(t, err)
+
+	_, err = api.RemoveInventoryItem("411898639737421824", "398197113495748626", "1", 1)
+	var apiErr *APIError
+	assert(t, errors.As(err, &apiErr), "expected *APIError, got %#v", err)
+	equals(t, int32(1), calls)
+}
+
+func TestInventoryChangesRejectInvalidQuantities(t *testing.T) {
+	api := Custom("token", setHangingClient())
+	_, err := api.AddInventoryItem("411898639737421824", "398197113495748626", "1", 0)
+	equals(t, ErrInvalidQuantity, err)
+	_, err = api.RemoveInventoryItem("411898639737421824", "398197113495748626", "1", -1)
+	equals(t, ErrInvalidQuantity, err)
+}
@@ -0,0 +1,261 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ErrInvalidItem is wrapped by every error returned for an item that cannot
+// be created or updated.
+var ErrInvalidItem = errors.New("v1: invalid item")
+
+// Item is an item in a guild's store.
+type Item struct {
+	// ID is assigned by the API; it is ignored by CreateItem.
+	ID          string `json:"id,omitempty"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Emoji is shown next to the item's name, e.g. "🍕" or "<:pizza:123>".
+	Emoji string `json:"emoji,omitempty"`
+	Price Amount `json:"price"`
+	// Stock is how many are left to buy, or nil for unlimited stock.
+	Stock *int `json:"stock_remaining"`
+	// Inventory reports whether bought items go to the buyer's inventory
+	// rather than being used straight away.
+	Inventory bool `json:"is_inventory"`
+	Usable    bool `json:"is_usable"`
+	Sellable  bool `json:"is_sellable"`
+	// Requirements must be met to buy the item.
+	Requirements ItemRequirements `json:"requirements"`
+	// Actions happen when the item is used.
+	Actions ItemActions `json:"actions"`
+	// ExpiresAt is when the item is removed from the store, or nil if never.
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// ItemRequirements are what a user needs to buy an item.
+type ItemRequirements struct {
+	// Roles the buyer must have all of.
+	Roles []string `json:"roles,omitempty"`
+	// ExcludedRoles the buyer must have none of.
+	ExcludedRoles []string `json:"excluded_roles,omitempty"`
+	// MinTotal is the lowest total balance the buyer may have, or nil.
+	MinTotal *Amount `json:"min_total,omitempty"`
+}
+
+// ItemActions are what happens when an item is used.
+type ItemActions struct {
+	// Reply is sent in the channel the item was used in.
+	Reply string `json:"reply,omitempty"`
+	// AddRoles are given to the user.
+	AddRoles []string `json:"add_roles,omitempty"`
+	// RemoveRoles are taken from the user.
+	RemoveRoles []string `json:"remove_roles,omitempty"`
+	// Cash is added to the user's cash, or nil.
+	Cash *Amount `json:"cash,omitempty"`
+	// Bank is added to the user's bank, or nil.
+	Bank *Amount `json:"bank,omitempty"`
+}
+
+func (it Item) validate() error {
+	switch {
+	case it.Name == "":
+		return fmt.Errorf("%w: missing name", ErrInvalidItem)
+	case it.Price.Sign() < 0 || it.Price.IsInf(0):
+		return fmt.Errorf("%w: price must be finite and not negative", ErrInvalidItem)
+	case it.Stock != nil && *it.Stock < 0:
+		return fmt.Errorf("%w: negative stock", ErrInvalidItem)
+	}
+	return nil
+}
+
+// ItemUpdate changes some fields of a store item. Nil fields are left
+// alone; use Ptr to fill them in:
+//
+//	api.UpdateItem(guildID, itemID, v1.ItemUpdate{Price: v1.Ptr(v1.NewAmount(250))})
+type ItemUpdate struct {
+	Name         *string
+	Description  *string
+	Emoji        *string
+	Price        *Amount
+	Stock        *int
+	Inventory    *bool
+	Usable       *bool
+	Sellable     *bool
+	Requirements *ItemRequirements
+	Actions      *ItemActions
+	ExpiresAt    *time.Time
+	// UnlimitedStock removes any stock limit. It conflicts with Stock.
+	UnlimitedStock bool
+	// NeverExpires removes any expiry. It conflicts with ExpiresAt.
+	NeverExpires bool
+}
+
+// Ptr returns a pointer to v, for filling in ItemUpdate.
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+func (u ItemUpdate) validate() error {
+	switch {
+	case u.Name != nil && *u.Name == "":
+		return fmt.Errorf("%w: empty name", ErrInvalidItem)
+	case u.Price != nil && (u.Price.Sign() < 0 || u.Price.IsInf(0)):
+		return fmt.Errorf("%w: price must be finite and not negative", ErrInvalidItem)
+	case u.Stock != nil && *u.Stock < 0:
+		return fmt.Errorf("%w: negative stock", ErrInvalidItem)
+	case u.Stock != nil && u.UnlimitedStock:
+		return fmt.Errorf("%w: both Stock and UnlimitedStock set", ErrInvalidItem)
+	case u.ExpiresAt != nil && u.NeverExpires:
+		return fmt.Errorf("%w: both ExpiresAt and NeverExpires set", ErrInvalidItem)
+	}
+	return nil
+}
+
+// MarshalJSON encodes only the fields being changed.
+func (u ItemUpdate) MarshalJSON() ([]byte, error) {
+	m := map[string]interface{}{}
+	set := func(key string, isSet bool, v interface{}) {
+		if isSet {
+			m[key] = v
+		}
+	}
+	set("name", u.Name != nil, u.Name)
+	set("description", u.Description != nil, u.Description)
+	set("emoji", u.Emoji != nil, u.Emoji)
+	set("price", u.Price != nil, u.Price)
+	set("stock_remaining", u.Stock != nil || u.UnlimitedStock, u.Stock)
+	set("is_inventory", u.Inventory != nil, u.Inventory)
+	set("is_usable", u.Usable != nil, u.Usable)
+	set("is_sellable", u.Sellable != nil, u.Sellable)
+	set("requirements", u.Requirements != nil, u.Requirements)
+	set("actions", u.Actions != nil, u.Actions)
+	set("expires_at", u.ExpiresAt != nil || u.NeverExpires, u.ExpiresAt)
+	return json.Marshal(m)
+}
+
+// ItemPage is one page of a guild's store.
+type ItemPage struct {
+	Items []Item
+	// TotalPages is the number of pages in the store, or 0 when the API
+	// didn't say.
+	TotalPages int
+}
+
+func itemsPath(guild string) string {
+	return fmt.Sprintf("/guilds/%v/items", guild)
+}
+
+func itemPath(guild, item string) string {
+	return fmt.Sprintf("/guilds/%v/items/%v", guild, url.PathEscape(item))
+}
+
+// ListItems fetches a page of a guild's store items.
+func (u *Client) ListItems(guild string, opts ListOptions) (ItemPage, error) {
+	return u.ListItemsContext(context.Background(), guild, opts)
+}
+
+// ListItemsContext is like ListItems but bound to ctx.
+func (u *Client) ListItemsContext(ctx context.Context, guild string, opts ListOptions) (ItemPage, error) {
+	query, err := opts.query()
+	if err != nil {
+		return ItemPage{}, err
+	}
+	op := &Operation{Name: "ListItems", Method: "GET", Path: itemsPath(guild) + query, GuildID: guild}
+	return call(ctx, u, op, func(data []byte) (ItemPage, error) {
+		items, totalPages, err := decodePage[Item](data, "items")
+		if err != nil {
+			return ItemPage{}, err
+		}
+		return ItemPage{Items: items, TotalPages: totalPages}, nil
+	})
+}
+
+// IterateItems returns an iterator over all of a guild's store items,
+// starting at opts.Page and fetching pages of opts.Limit items as needed.
+func (u *Client) IterateItems(guild string, opts ListOptions) *Iterator[Item] {
+	return u.IterateItemsContext(context.Background(), guild, opts)
+}
+
+// IterateItemsContext is like IterateItems but every page is fetched with
+// ctx.
+func (u *Client) IterateItemsContext(ctx context.Context, guild string, opts ListOptions) *Iterator[Item] {
+	return NewIterator(ctx, opts, func(ctx context.Context, opts ListOptions) ([]Item, int, error) {
+		page, err := u.ListItemsContext(ctx, guild, opts)
+		return page.Items, page.TotalPages, err
+	})
+}
+
+// GetItem fetches one of a guild's store items.
+func (u *Client) GetItem(guild, item string) (Item, error) {
+	return u.GetItemContext(context.Background(), guild, item)
+}
+
+// GetItemContext is like GetItem but bound to ctx.
+func (u *Client) GetItemContext(ctx context.Context, guild, item string) (Item, error) {
+	op := &Operation{Name: "GetItem", Method: "GET", Path: itemPath(guild, item), GuildID: guild}
+	return call(ctx, u, op, decodeItem)
+}
+
+// CreateItem adds item to a guild's store and returns it as created,
+// with its ID filled in.
+func (u *Client) CreateItem(guild string, item Item) (Item, error) {
+	return u.CreateItemContext(context.Background(), guild, item)
+}
+
+// CreateItemContext is like CreateItem but bound to ctx.
+func (u *Client) CreateItemContext(ctx context.Context, guild string, item Item) (Item, error) {
+	if err := item.validate(); err != nil {
+		return Item{}, err
+	}
+	item.ID = ""
+	value, err := json.Marshal(item)
+	if err != nil {
+		return Item{}, err
+	}
+	op := &Operation{Name: "CreateItem", Method: http.MethodPost, Path: itemsPath(guild), GuildID: guild, Payload: value}
+	return call(ctx, u, op, decodeItem)
+}
+
+// UpdateItem changes a store item and returns it as updated.
+func (u *Client) UpdateItem(guild, item string, update ItemUpdate) (Item, error) {
+	return u.UpdateItemContext(context.Background(), guild, item, update)
+}
+
+// UpdateItemContext is like UpdateItem but bound to ctx.
+func (u *Client) UpdateItemContext(ctx context.Context, guild, item string, update ItemUpdate) (Item, error) {
+	if err := update.validate(); err != nil {
+		return Item{}, err
+	}
+	value, err := json.Marshal(update)
+	if err != nil {
+		return Item{}, err
+	}
+	op := &Operation{Name: "UpdateItem", Method: http.MethodPatch, Path: itemPath(guild, item), GuildID: guild, Payload: value}
+	return call(ctx, u, op, decodeItem)
+}
+
+// DeleteItem removes an item from a guild's store.
+func (u *Client) DeleteItem(guild, item string) error {
+	return u.DeleteItemContext(context.Background(), guild, item)
+}
+
+// DeleteItemContext is like DeleteItem but bound to ctx.
+func (u *Client) DeleteItemContext(ctx context.Context, guild, item string) error {
+	op := &Operation{Name: "DeleteItem", Method: http.MethodDelete, Path: itemPath(guild, item), GuildID: guild}
+	_, err := call[struct{}](ctx, u, op, nil)
+	return err
+}
+
+func decodeItem(data []byte) (Item, error) {
+	var item Item
+	if err := json.Unmarshal(data, &item); err != nil {
+		return Item{}, err
+	}
+	return item, nil
+}
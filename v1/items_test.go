@@ -0,0 +1,155 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const testItem = `{"id":"1","name":"Pizza","description":"Hot and fresh","emoji":"🍕","price":250,"stock_remaining":10,"is_inventory":true,"is_usable":true,"is_sellable":false,"requirements":{"roles":["411898639737421825"]},"actions":{"reply":"Yum!","cash":"5"},"expires_at":"2030-01-02T03:04:05Z"}`
+
+// Records the last request and replies with body.
+func newItemServer(t *testing.T, code int, body string, method, path, payload *string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		ok(t, err)
+		*method, *path, *payload = r.Method, r.URL.RequestURI(), string(b)
+		w.WriteHeader(code)
+		w.Write([]byte(body))
+	}))
+}
+
+func TestGetItemHandlesDataOnSuccessfulFetchCorrectly(t *testing.T) {
+	var method, path, payload string
+	server := newItemServer(t, 200, testItem, &method, &path, &payload)
+	defer server.Close()
+	api, err := NewClient("token", WithBaseURL(server.URL))
+	ok(t, err)
+
+	item, err := api.GetItem("411898639737421824", "1")
+	ok(t, err)
+	equals(t, "GET", method)
+	equals(t, "/guilds/411898639737421824/items/1", path)
+	expires := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	equals(t, Item{
+		ID: "1", Name: "Pizza", Description: "Hot and fresh", Emoji: "🍕",
+		Price: NewAmount(250), Stock: Ptr(10), Inventory: true, Usable: true,
+		Requirements: ItemRequirements{Roles: []string{"411898639737421825"}},
+		Actions:      ItemActions{Reply: "Yum!", Cash: Ptr(NewAmount(5))},
+		ExpiresAt:    &expires,
+	}, item)
+}
+
+func TestListItemsSendsOptionsAndDecodesPage(t *testing.T) {
+	var method, path, payload string
+	server := newItemServer(t, 200, `{"items":[`+testItem+`],"total_pages":4}`, &method, &path, &payload)
+	defer server.Close()
+	api, err := NewClient("token", WithBaseURL(server.URL))
+	ok(t, err)
+
+	page, err := api.ListItems("411898639737421824", ListOptions{Limit: 1, Page: 2})
+	ok(t, err)
+	equals(t, "/guilds/411898639737421824/items?limit=1&page=2", path)
+	equals(t, 4, page.TotalPages)
+	equals(t, 1, len(page.Items))
+	equals(t, "Pizza", page.Items[0].Name)
+}
+
+func TestIterateItemsWalksAllPages(t *testing.T) {
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"items":       []Item{{ID: page, Name: "Item " + page}},
+			"total_pages": 3,
+		})
+	}))
+	defer server.Close()
+	api, err := NewClient("token", WithBaseURL(server.URL))
+	ok(t, err)
+
+	it := api.IterateItems("411898639737421824", ListOptions{Limit: 1})
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Value().ID)
+	}
+	ok(t, it.Err())
+	equals(t, []string{"1", "2", "3"}, ids)
+	equals(t, []string{"1", "2", "3"}, pages)
+}
+
+func TestCreateItemPostsItemWithoutID(t *testing.T) {
+	var method, path, payload string
+	server := newItemServer(t, 200, testItem, &method, &path, &payload)
+	defer server.Close()
+	api, err := NewClient("token", WithBaseURL(server.URL))
+	ok(t, err)
+
+	item, err := api.CreateItem("411898639737421824", Item{ID: "ignored", Name: "Pizza", Price: NewAmount(250)})
+	ok(t, err)
+	equals(t, "1", item.ID)
+	equals(t, "POST", method)
+	equals(t, "/guilds/411898639737421824/items", path)
+	equals(t, `{"name":"Pizza","price":250,"stock_remaining":null,"is_inventory":false,"is_usable":false,"is_sellable":false,"requirements":{},"actions":{},"expires_at":null}`, payload)
+}
+
+func TestCreateItemRejectsInvalidItemsBeforeSending(t *testing.T) {
+	api := Custom("token", setHangingClient())
+	for name, item := range map[string]Item{
+		"no name":        {Price: NewAmount(1)},
+		"negative price": {Name: "Pizza", Price: NewAmount(-1)},
+		"infinite price": {Name: "Pizza", Price: Inf(1)},
+		"negative stock": {Name: "Pizza", Stock: Ptr(-1)},
+	} {
+		_, err := api.CreateItem("411898639737421824", item)
+		assert(t, errors.Is(err, ErrInvalidItem), "%s: expected ErrInvalidItem, got %v", name, err)
+	}
+}
+
+func TestUpdateItemPatchesOnlyChangedFields(t *testing.T) {
+	var method, path, payload string
+	server := newItemServer(t, 200, testItem, &method, &path, &payload)
+	defer server.Close()
+	api, err := NewClient("token", WithBaseURL(server.URL))
+	ok(t, err)
+
+	_, err = api.UpdateItem("411898639737421824", "1", ItemUpdate{Price: Ptr(NewAmount(300)), Sellable: Ptr(true), UnlimitedStock: true})
+	ok(t, err)
+	equals(t, "PATCH", method)
+	equals(t, "/guilds/411898639737421824/items/1", path)
+	equals(t, `{"is_sellable":true,"price":300,"stock_remaining":null}`, payload)
+
+	_, err = api.UpdateItem("411898639737421824", "1", ItemUpdate{Stock: Ptr(5), UnlimitedStock: true})
+	assert(t, errors.Is(err, ErrInvalidItem), "expected ErrInvalidItem, got %v", err)
+}
+
+func TestDeleteItemSendsDelete(t *testing.T) {
+	var method, path, payload string
+	server := newItemServer(t, 204, ``, &method, &path, &payload)
+	defer server.Close()
+	api, err := NewClient("token", WithBaseURL(server.URL))
+	ok(t, err)
+
+	ok(t, api.DeleteItem("411898639737421824", "item/1"))
+	equals(t, "DELETE", method)
+	equals(t, "/guilds/411898639737421824/items/item%2F1", path)
+}
+
+func TestGetItemHandlesErrorOnUnknownItem(t *testing.T) {
+	var method, path, payload string
+	server := newItemServer(t, 404, `{"error":"404: Not found","message":"Unknown item"}`, &method, &path, &payload)
+	defer server.Close()
+	api, err := NewClient("token", WithBaseURL(server.URL))
+	ok(t, err)
+
+	item, err := api.GetItem("411898639737421824", "999")
+	equals(t, Item{}, item)
+	var apiErr *APIError
+	assert(t, errors.As(err, &apiErr), "expected *APIError, got %#v", err)
+	equals(t, "Unknown item", apiErr.Message)
+}
@@ -0,0 +1,159 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Iterator walks a paginated list, fetching pages lazily as Next reaches
+// them, so lists of any size can be processed in constant memory:
+//
+//	it := api.IterateLeaderboard(guildID, v1.LeaderboardOptions{Limit: 100})
+//	for it.Next() {
+//		entry := it.Value()
+//		// ...
+//	}
+//	if err := it.Err(); err != nil {
+//		// ...
+//	}
+//
+// An Iterator is not safe for concurrent use.
+type Iterator[T any] struct {
+	ctx      context.Context
+	pageSize int
+	fetch    func(ctx context.Context, n int) (items []T, totalPages int, err error)
+
+	page  int // pages fetched so far
+	skip  int // items still to drop from the start of the list
+	items []T
+	cur   T
+	done  bool
+	err   error
+}
+
+// newIterator returns an iterator that calls fetch with 0, 1, 2... for each
+// page. The walk stops after the last of totalPages, when fetch returns an
+// empty page, or, if pageSize is known, a page with fewer items than that.
+func newIterator[T any](ctx context.Context, pageSize int, fetch func(ctx context.Context, n int) ([]T, int, error)) *Iterator[T] {
+	return &Iterator[T]{ctx: ctx, pageSize: pageSize, fetch: fetch}
+}
+
+// Next advances to the next item, fetching the next page if needed. It
+// returns false at the end of the list or on error; check Err to tell them
+// apart.
+func (it *Iterator[T]) Next() bool {
+	for len(it.items) == 0 {
+		if it.done {
+			return false
+		}
+		items, totalPages, err := it.fetch(it.ctx, it.page)
+		if err != nil {
+			it.err, it.done = err, true
+			return false
+		}
+		it.page++
+		it.items = items
+		switch {
+		case len(items) == 0:
+			it.done = true
+		case totalPages > 0:
+			it.done = it.page >= totalPages
+		case it.pageSize > 0:
+			it.done = len(items) < it.pageSize
+		}
+		if it.skip > 0 {
+			n := min(it.skip, len(items))
+			it.items, it.skip = items[n:], it.skip-n
+		}
+	}
+	it.cur, it.items = it.items[0], it.items[1:]
+	return true
+}
+
+// Value returns the current item.
+func (it *Iterator[T]) Value() T {
+	return it.cur
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// Pages returns the number of pages fetched so far.
+func (it *Iterator[T]) Pages() int {
+	return it.page
+}
+
+// ListOptions selects a page of a paginated list such as ListItems.
+type ListOptions struct {
+	// Limit is the number of items per page. Zero means the API default.
+	Limit int
+	// Page is the page to fetch, starting at 1. Zero means the first page.
+	Page int
+}
+
+func (o ListOptions) query() (string, error) {
+	if o.Limit < 0 || o.Page < 0 {
+		return "", fmt.Errorf("v1: negative limit or page")
+	}
+	v := url.Values{}
+	if o.Limit > 0 {
+		v.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Page > 0 {
+		v.Set("page", strconv.Itoa(o.Page))
+	}
+	if len(v) == 0 {
+		return "", nil
+	}
+	return "?" + v.Encode(), nil
+}
+
+// NewIterator returns an iterator that fetches pages with fetch, starting at
+// opts.Page. fetch returns the items on the page and, if known, the total
+// number of pages. It lets implementations of API other than Client, such as
+// fakes, provide the Iterate methods.
+func NewIterator[T any](ctx context.Context, opts ListOptions, fetch func(ctx context.Context, opts ListOptions) ([]T, int, error)) *Iterator[T] {
+	first := opts.Page
+	if first == 0 {
+		first = 1
+	}
+	return newIterator(ctx, opts.Limit, func(ctx context.Context, n int) ([]T, int, error) {
+		opts.Page = first + n
+		items, totalPages, err := fetch(ctx, opts)
+		if totalPages > 0 {
+			// Count pages from where we started.
+			totalPages -= first - 1
+		}
+		return items, totalPages, err
+	})
+}
+
+// decodePage decodes a page of a list, which the API sends either as a bare
+// array or as an object holding the array under key along with
+// "total_pages". totalPages is 0 when the API didn't say.
+func decodePage[T any](data []byte, key string) (items []T, totalPages int, err error) {
+	if len(data) > 0 && data[0] == '[' {
+		err = json.Unmarshal(data, &items)
+		return items, 0, err
+	}
+	var page map[string]json.RawMessage
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, 0, err
+	}
+	if raw, ok := page[key]; ok {
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, 0, err
+		}
+	}
+	if raw, ok := page["total_pages"]; ok {
+		if err := json.Unmarshal(raw, &totalPages); err != nil {
+			return nil, 0, err
+		}
+	}
+	return items, totalPages, nil
+}
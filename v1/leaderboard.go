@@ -0,0 +1,137 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Leaderboard sort orders, for LeaderboardOptions.Sort.
+const (
+	SortByTotal = "total"
+	SortByCash  = "cash"
+	SortByBank  = "bank"
+)
+
+// LeaderboardOptions selects which part of a guild leaderboard to fetch. The
+// zero value fetches the API's default page, sorted by total.
+type LeaderboardOptions struct {
+	// Sort is SortByTotal, SortByCash or SortByBank. Empty means total.
+	Sort string
+	// Limit is the number of entries per page. Zero means the API default.
+	Limit int
+	// Offset skips that many entries before the first page.
+	Offset int
+	// Page is the page to fetch, starting at 1. Zero means the first page.
+	Page int
+}
+
+func (o LeaderboardOptions) query() (string, error) {
+	v := url.Values{}
+	switch o.Sort {
+	case "":
+	case SortByTotal, SortByCash, SortByBank:
+		v.Set("sort", o.Sort)
+	default:
+		return "", fmt.Errorf("v1: invalid leaderboard sort %q", o.Sort)
+	}
+	for _, p := range []struct {
+		name  string
+		value int
+	}{{"limit", o.Limit}, {"offset", o.Offset}, {"page", o.Page}} {
+		if p.value < 0 {
+			return "", fmt.Errorf("v1: negative leaderboard %s", p.name)
+		}
+		if p.value > 0 {
+			v.Set(p.name, strconv.Itoa(p.value))
+		}
+	}
+	if len(v) == 0 {
+		return "", nil
+	}
+	return "?" + v.Encode(), nil
+}
+
+// LeaderboardPage is one page of a guild leaderboard.
+type LeaderboardPage struct {
+	Entries []LeaderboardEntry
+	// TotalPages is the number of pages in the leaderboard, or 0 when the API
+	// didn't say.
+	TotalPages int
+
+	// bare is set when the API sent a bare array, ignoring any page asked
+	// for.
+	bare bool
+}
+
+// UnmarshalJSON decodes either a bare array of entries or the
+// {"users":[...],"total_pages":n} object the API sends for paged requests.
+func (p *LeaderboardPage) UnmarshalJSON(data []byte) error {
+	entries, totalPages, err := decodePage[LeaderboardEntry](data, "users")
+	if err != nil {
+		return err
+	}
+	*p = LeaderboardPage{Entries: entries, TotalPages: totalPages, bare: len(data) > 0 && data[0] == '['}
+	return nil
+}
+
+// GetLeaderboardPage fetches the part of a guild leaderboard selected by
+// opts.
+func (u *Client) GetLeaderboardPage(guild string, opts LeaderboardOptions) (LeaderboardPage, error) {
+	return u.GetLeaderboardPageContext(context.Background(), guild, opts)
+}
+
+// GetLeaderboardPageContext is like GetLeaderboardPage but bound to ctx.
+func (u *Client) GetLeaderboardPageContext(ctx context.Context, guild string, opts LeaderboardOptions) (LeaderboardPage, error) {
+	query, err := opts.query()
+	if err != nil {
+		return LeaderboardPage{}, err
+	}
+	op := &Operation{Name: "GetLeaderboardPage", Method: "GET", Path: fmt.Sprintf("/guilds/%v/users%v", guild, query), GuildID: guild}
+	return call(ctx, u, op, func(data []byte) (LeaderboardPage, error) {
+		var page LeaderboardPage
+		if err := json.Unmarshal(data, &page); err != nil {
+			return LeaderboardPage{}, err
+		}
+		return page, nil
+	})
+}
+
+// IterateLeaderboard returns an iterator over a whole guild leaderboard,
+// starting at opts.Page and fetching pages of opts.Limit entries as they are
+// needed. opts.Offset entries are skipped from the start. If the API answers
+// with a bare array rather than a page, that is taken as the whole list.
+func (u *Client) IterateLeaderboard(guild string, opts LeaderboardOptions) *Iterator[LeaderboardEntry] {
+	return u.IterateLeaderboardContext(context.Background(), guild, opts)
+}
+
+// IterateLeaderboardContext is like IterateLeaderboard but every page is
+// fetched with ctx.
+func (u *Client) IterateLeaderboardContext(ctx context.Context, guild string, opts LeaderboardOptions) *Iterator[LeaderboardEntry] {
+	lo := ListOptions{Limit: opts.Limit, Page: opts.Page}
+	var skip int
+	if opts.Offset > 0 {
+		// Rather than send the offset with every page, start at the page it
+		// falls on and skip the entries before it, so pages line up however
+		// the API combines offset and page.
+		skip, opts.Offset = opts.Offset, 0
+		if lo.Limit > 0 {
+			lo.Page = max(lo.Page, 1) + skip/lo.Limit
+			skip %= lo.Limit
+		}
+	}
+	it := NewIterator(ctx, lo, func(ctx context.Context, lo ListOptions) ([]LeaderboardEntry, int, error) {
+		opts.Page = lo.Page
+		page, err := u.GetLeaderboardPageContext(ctx, guild, opts)
+		if page.bare {
+			// The API ignored the page, so asking for the next one would
+			// only fetch the same entries again.
+			return page.Entries, lo.Page, err
+		}
+		return page.Entries, page.TotalPages, err
+	})
+	it.skip = skip
+	return it
+}
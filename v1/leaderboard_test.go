@@ -0,0 +1,185 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// Serves a leaderboard of size users in pages, as the paged object when
+// withTotal is set and as bare arrays otherwise.
+func newLeaderboardServer(t *testing.T, size int, withTotal bool, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		equals(t, "/guilds/411898639737421824/users", r.URL.Path)
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+		var entries []map[string]interface{}
+		for rank := (page-1)*limit + 1; rank <= page*limit && rank <= size; rank++ {
+			entries = append(entries, map[string]interface{}{
+				"rank": strconv.Itoa(rank), "user_id": fmt.Sprint(rank), "cash": rank, "bank": 0, "total": rank,
+			})
+		}
+		if entries == nil {
+			entries = []map[string]interface{}{}
+		}
+		if withTotal {
+			json.NewEncoder(w).Encode(map[string]interface{}{"users": entries, "total_pages": (size + limit - 1) / limit})
+			return
+		}
+		json.NewEncoder(w).Encode(entries)
+	}))
+}
+
+func TestLeaderboardOptionsQuery(t *testing.T) {
+	q, err := LeaderboardOptions{}.query()
+	ok(t, err)
+	equals(t, "", q)
+	q, err = LeaderboardOptions{Sort: SortByCash, Limit: 50, Offset: 10, Page: 2}.query()
+	ok(t, err)
+	equals(t, "?limit=50&offset=10&page=2&sort=cash", q)
+
+	_, err = LeaderboardOptions{Sort: "rank"}.query()
+	assert(t, err != nil, "expected an error for an invalid sort")
+	_, err = LeaderboardOptions{Limit: -1}.query()
+	assert(t, err != nil, "expected an error for a negative limit")
+}
+
+func TestGetLeaderboardPageSendsOptionsAndDecodesPagedResponse(t *testing.T) {
+	var calls int32
+	server := newLeaderboardServer(t, 7, true, &calls)
+	defer server.Close()
+	api, err := NewClient("token", WithBaseURL(server.URL))
+	ok(t, err)
+
+	page, err := api.GetLeaderboardPage("411898639737421824", LeaderboardOptions{Limit: 3, Page: 3})
+	ok(t, err)
+	equals(t, 3, page.TotalPages)
+	equals(t, 1, len(page.Entries))
+	equals(t, 7, page.Entries[0].Rank)
+	equals(t, NewAmount(7), page.Entries[0].Cash)
+}
+
+func TestGetLeaderboardPageRejectsInvalidOptionsBeforeSending(t *testing.T) {
+	var calls int32
+	server := newLeaderboardServer(t, 7, true, &calls)
+	defer server.Close()
+	api, err := NewClient("token", WithBaseURL(server.URL))
+	ok(t, err)
+
+	_, err = api.GetLeaderboardPage("411898639737421824", LeaderboardOptions{Sort: "rank"})
+	assert(t, err != nil, "expected an error for an invalid sort")
+	equals(t, int32(0), calls)
+}
+
+func TestIterateLeaderboardWalksAllPages(t *testing.T) {
+	var calls int32
+	server := newLeaderboardServer(t, 7, true, &calls)
+	defer server.Close()
+	api, err := NewClient("token", WithBaseURL(server.URL))
+	ok(t, err)
+
+	it := api.IterateLeaderboard("411898639737421824", LeaderboardOptions{Limit: 3})
+	var ranks []int
+	for it.Next() {
+		ranks = append(ranks, it.Value().Rank)
+	}
+	ok(t, it.Err())
+	equals(t, []int{1, 2, 3, 4, 5, 6, 7}, ranks)
+	equals(t, 3, it.Pages())
+	equals(t, int32(3), calls)
+}
+
+func TestIterateLeaderboardStopsAfterBareArray(t *testing.T) {
+	var calls int32
+	server := newLeaderboardServer(t, 7, false, &calls)
+	defer server.Close()
+	api, err := NewClient("token", WithBaseURL(server.URL))
+	ok(t, err)
+
+	it := api.IterateLeaderboard("411898639737421824", LeaderboardOptions{Limit: 3})
+	var ranks []int
+	for it.Next() {
+		ranks = append(ranks, it.Value().Rank)
+	}
+	ok(t, it.Err())
+	equals(t, []int{1, 2, 3}, ranks)
+	equals(t, int32(1), calls)
+}
+
+func TestIterateLeaderboardSkipsOffsetWithoutSendingIt(t *testing.T) {
+	var calls int32
+	server := newLeaderboardServer(t, 7, true, &calls)
+	defer server.Close()
+	api, err := NewClient("token", WithBaseURL(server.URL), WithMiddleware(func(next Handler) Handler {
+		return func(ctx context.Context, op *Operation) (interface{}, error) {
+			assert(t, !strings.Contains(op.Path, "offset"), "offset sent in %s", op.Path)
+			return next(ctx, op)
+		}
+	}))
+	ok(t, err)
+
+	it := api.IterateLeaderboard("411898639737421824", LeaderboardOptions{Limit: 3, Offset: 4})
+	var ranks []int
+	for it.Next() {
+		ranks = append(ranks, it.Value().Rank)
+	}
+	ok(t, it.Err())
+	equals(t, []int{5, 6, 7}, ranks)
+	equals(t, int32(2), calls)
+}
+
+func TestIterateLeaderboardStartsAtPage(t *testing.T) {
+	var calls int32
+	server := newLeaderboardServer(t, 7, true, &calls)
+	defer server.Close()
+	api, err := NewClient("token", WithBaseURL(server.URL))
+	ok(t, err)
+
+	it := api.IterateLeaderboard("411898639737421824", LeaderboardOptions{Limit: 3, Page: 2})
+	var ranks []int
+	for it.Next() {
+		ranks = append(ranks, it.Value().Rank)
+	}
+	ok(t, it.Err())
+	equals(t, []int{4, 5, 6, 7}, ranks)
+	equals(t, int32(2), calls)
+}
+
+func TestIterateLeaderboardFetchesLazily(t *testing.T) {
+	var calls int32
+	server := newLeaderboardServer(t, 7, true, &calls)
+	defer server.Close()
+	api, err := NewClient("token", WithBaseURL(server.URL))
+	ok(t, err)
+
+	it := api.IterateLeaderboard("411898639737421824", LeaderboardOptions{Limit: 3})
+	equals(t, int32(0), calls)
+	for i := 0; i < 3; i++ {
+		assert(t, it.Next(), "expected entry %d", i)
+	}
+	equals(t, int32(1), calls)
+	assert(t, it.Next(), "expected a fourth entry")
+	equals(t, int32(2), calls)
+}
+
+func TestIterateLeaderboardStopsOnError(t *testing.T) {
+	client := setClient(200, "/guilds/000000000000000000/users", `{"error":"404: Not found","message":"Unknown guild"}`)
+	api := Custom("token", client)
+
+	it := api.IterateLeaderboard("000000000000000000", LeaderboardOptions{})
+	equals(t, false, it.Next())
+	var apiErr *APIError
+	assert(t, errors.As(it.Err(), &apiErr), "expected *APIError, got %#v", it.Err())
+	equals(t, false, it.Next())
+}
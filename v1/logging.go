@@ -0,0 +1,131 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// LogLevels sets the level of each kind of record the client logs. Start
+// from DefaultLogLevels and change what you need:
+//
+//	levels := v1.DefaultLogLevels()
+//	levels.Success = slog.LevelInfo
+//	api, err := v1.NewClient(token, v1.WithLogger(logger), v1.WithLogLevels(levels))
+type LogLevels struct {
+	// Success is used for calls that succeeded.
+	Success slog.Level
+	// ClientError is used for calls the API rejected with a 4xx status
+	// other than 429, such as unknown users or missing permissions.
+	ClientError slog.Level
+	// RateLimited is used for calls that failed with 429 Too Many Requests.
+	RateLimited slog.Level
+	// Failure is used for calls that failed with a server error, a network
+	// error or a cancelled context.
+	Failure slog.Level
+	// Retry is used for each retry and each wait for the rate limiter.
+	Retry slog.Level
+}
+
+// DefaultLogLevels returns the levels used unless WithLogLevels is given:
+// successes and retries at Debug, rejections and rate limits at Warn and
+// failures at Error.
+func DefaultLogLevels() LogLevels {
+	return LogLevels{
+		Success:     slog.LevelDebug,
+		ClientError: slog.LevelWarn,
+		RateLimited: slog.LevelWarn,
+		Failure:     slog.LevelError,
+		Retry:       slog.LevelDebug,
+	}
+}
+
+// WithLogLevels sets the levels the client logs at. It has no effect
+// without WithLogger.
+func WithLogLevels(levels LogLevels) Option {
+	return func(c *Client) error {
+		c.logLevels = levels
+		return nil
+	}
+}
+
+// logCall wraps h to log one record per call with what happened to it.
+func (u *Client) logCall(h Handler) Handler {
+	return func(ctx context.Context, op *Operation) (interface{}, error) {
+		start := time.Now()
+		res, err := h(ctx, op)
+		latency := time.Since(start)
+
+		level, msg := u.logLevels.Success, "unb: call succeeded"
+		var rateErr *RateLimitError
+		var apiErr *APIError
+		switch {
+		case err == nil:
+		case op.Name == "Check" && op.StatusCode == http.StatusNotFound:
+			// The API root 404s when it is up.
+		case errors.As(err, &rateErr):
+			level, msg = u.logLevels.RateLimited, "unb: call rate limited"
+		case errors.As(err, &apiErr) && apiErr.StatusCode < 500:
+			level, msg = u.logLevels.ClientError, "unb: call rejected"
+		default:
+			level, msg = u.logLevels.Failure, "unb: call failed"
+		}
+		if !u.logger.Enabled(ctx, level) {
+			return res, err
+		}
+		attrs := []slog.Attr{
+			slog.String("operation", op.Name),
+			slog.String("method", op.Method),
+			slog.String("path", u.redact(op.Path)),
+		}
+		if op.GuildID != "" {
+			attrs = append(attrs, slog.String("guild_id", op.GuildID))
+		}
+		if op.UserID != "" {
+			attrs = append(attrs, slog.String("user_id", op.UserID))
+		}
+		attrs = append(attrs,
+			slog.Int("status", op.StatusCode),
+			slog.Duration("latency", latency),
+			slog.Int("retries", max(op.Attempts-1, 0)),
+		)
+		if op.Bucket != "" {
+			attrs = append(attrs, slog.String("bucket", op.Bucket))
+		}
+		if op.RateLimited > 0 {
+			attrs = append(attrs, slog.Int("rate_limited", op.RateLimited))
+		}
+		if op.RateLimitWait > 0 {
+			attrs = append(attrs, slog.Duration("rate_limit_wait", op.RateLimitWait))
+		}
+		if op.RetryAfterWait > 0 {
+			attrs = append(attrs, slog.Duration("retry_after_wait", op.RetryAfterWait))
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", u.redact(err.Error())))
+		}
+		u.logger.LogAttrs(ctx, level, msg, attrs...)
+		return res, err
+	}
+}
+
+// redact removes the token from s, in case it ever finds its way into a
+// path or error message.
+func (u *Client) redact(s string) string {
+	if u.token == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, u.token, "[REDACTED]")
+}
+
+// LogValue implements slog.LogValuer so that logging a Client never logs
+// its token.
+func (u *Client) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("base_url", u.baseURL),
+		slog.String("token", "[REDACTED]"),
+	)
+}
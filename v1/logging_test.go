@@ -0,0 +1,75 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newLoggedClient(t *testing.T, code int, body string, opts ...Option) (*Client, *bytes.Buffer) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Bucket", "balances")
+		w.WriteHeader(code)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	api, err := NewClient("s3cret", append([]Option{WithBaseURL(server.URL), WithLogger(logger)}, opts...)...)
+	ok(t, err)
+	return api, &buf
+}
+
+func decodeRecord(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	var record map[string]interface{}
+	ok(t, json.Unmarshal(buf.Bytes(), &record))
+	return record
+}
+
+func TestWithLoggerLogsEachCall(t *testing.T) {
+	api, buf := newLoggedClient(t, 200, testBalance)
+
+	_, err := api.GetBalance("411898639737421824", "398197113495748626")
+	ok(t, err)
+	record := decodeRecord(t, buf)
+	equals(t, "DEBUG", record["level"])
+	equals(t, "unb: call succeeded", record["msg"])
+	equals(t, "GetBalance", record["operation"])
+	equals(t, "GET", record["method"])
+	equals(t, "/guilds/411898639737421824/users/398197113495748626", record["path"])
+	equals(t, "411898639737421824", record["guild_id"])
+	equals(t, "398197113495748626", record["user_id"])
+	equals(t, float64(200), record["status"])
+	equals(t, float64(0), record["retries"])
+	equals(t, "balances", record["bucket"])
+	_, hasLatency := record["latency"]
+	assert(t, hasLatency, "expected latency in %v", record)
+}
+
+func TestWithLogLevelsTunesLevels(t *testing.T) {
+	levels := DefaultLogLevels()
+	levels.ClientError = slog.LevelInfo
+	api, buf := newLoggedClient(t, 403, `{"error":"403: Forbidden","message":"Missing Permissions for s3cret"}`, WithLogLevels(levels))
+
+	_, err := api.GetGuild("411898639737421824")
+	assert(t, err != nil, "expected an error")
+	record := decodeRecord(t, buf)
+	equals(t, "INFO", record["level"])
+	equals(t, "unb: call rejected", record["msg"])
+	assert(t, !strings.Contains(buf.String(), "s3cret"), "token leaked into logs: %q", buf.String())
+	assert(t, strings.Contains(buf.String(), "[REDACTED]"), "expected the token to be redacted: %q", buf.String())
+}
+
+func TestClientLogValueRedactsToken(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	api, err := NewClient("s3cret")
+	ok(t, err)
+
+	logger.Info("client", "api", api)
+	assert(t, !strings.Contains(buf.String(), "s3cret"), "token leaked into logs: %q", buf.String())
+}
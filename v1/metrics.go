@@ -0,0 +1,68 @@
+package v1
+
+import (
+	"context"
+	"time"
+)
+
+// CallStats describes one finished call, for Metrics.
+type CallStats struct {
+	// Operation is the call's Operation.Name, such as "GetBalance".
+	Operation string
+	// StatusCode is the HTTP status of the last response, or 0 if none was
+	// received. A 429 that a retry got past is counted in RateLimited.
+	StatusCode int
+	// Err is the error the call returned, if any.
+	Err error
+	// Latency is how long the call took, including retries and waits.
+	Latency time.Duration
+	// Retries is the number of requests sent after the first.
+	Retries int
+	// RateLimited is the number of responses that were 429 Too Many
+	// Requests.
+	RateLimited int
+	// RateLimitWait is the time spent holding requests back for the rate
+	// limiter.
+	RateLimitWait time.Duration
+	// RetryAfterWait is the time spent waiting out the retry-after of 429s.
+	RetryAfterWait time.Duration
+}
+
+// Metrics receives measurements of every call the client makes, so that
+// latency and rate limiting can be graphed and alerted on. The unbprom
+// package collects them for Prometheus.
+//
+// Implementations must be safe for concurrent use, and should return
+// quickly as they are called before the call returns.
+type Metrics interface {
+	// ObserveCall records a finished call.
+	ObserveCall(stats CallStats)
+}
+
+// WithMetrics sets where the client reports its calls. By default nothing is
+// measured.
+func WithMetrics(metrics Metrics) Option {
+	return func(c *Client) error {
+		c.metrics = metrics
+		return nil
+	}
+}
+
+// measureCall wraps h to report each call to the client's metrics.
+func (u *Client) measureCall(h Handler) Handler {
+	return func(ctx context.Context, op *Operation) (interface{}, error) {
+		start := time.Now()
+		res, err := h(ctx, op)
+		u.metrics.ObserveCall(CallStats{
+			Operation:      op.Name,
+			StatusCode:     op.StatusCode,
+			Err:            err,
+			Latency:        time.Since(start),
+			Retries:        max(op.Attempts-1, 0),
+			RateLimited:    op.RateLimited,
+			RateLimitWait:  op.RateLimitWait,
+			RetryAfterWait: op.RetryAfterWait,
+		})
+		return res, err
+	}
+}
@@ -0,0 +1,56 @@
+package v1
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingMetrics keeps every call it observes.
+type recordingMetrics struct {
+	mu    sync.Mutex
+	calls []CallStats
+}
+
+func (m *recordingMetrics) ObserveCall(stats CallStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, stats)
+}
+
+func TestWithMetricsObservesEachCall(t *testing.T) {
+	var calls int32
+	metrics := &recordingMetrics{}
+	api, err := NewClient("token",
+		WithHTTPClient(setSequenceClient(&calls,
+			cannedResponse{429, `{"message":"You are being rate limited.","retry_after":5}`},
+			cannedResponse{200, testBalance},
+			cannedResponse{404, `{"error":"404: Not found","message":"Unknown user"}`},
+		)),
+		WithRetryPolicy(RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond}),
+		WithMetrics(metrics),
+	)
+	ok(t, err)
+
+	_, err = api.GetBalance("411898639737421824", "398197113495748626")
+	ok(t, err)
+	_, err = api.GetGuild("411898639737421824")
+	assert(t, err != nil, "expected an error")
+	equals(t, 2, len(metrics.calls))
+
+	first := metrics.calls[0]
+	equals(t, "GetBalance", first.Operation)
+	equals(t, 200, first.StatusCode)
+	equals(t, nil, first.Err)
+	equals(t, 1, first.Retries)
+	equals(t, 1, first.RateLimited)
+	assert(t, first.RetryAfterWait >= 5*time.Millisecond, "expected the retry-after to count as waiting, got %v", first.RetryAfterWait)
+	assert(t, first.Latency >= first.RetryAfterWait, "expected latency to include the wait, got %v", first.Latency)
+
+	second := metrics.calls[1]
+	equals(t, "GetGuild", second.Operation)
+	equals(t, 404, second.StatusCode)
+	equals(t, 0, second.Retries)
+	equals(t, 0, second.RateLimited)
+	assert(t, second.Err != nil, "expected the error to be observed")
+}
@@ -0,0 +1,134 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Operation describes one API call as it passes through the client's
+// middleware.
+type Operation struct {
+	// Name is the endpoint method without its Context suffix, such as
+	// "GetBalance". Calls made with Request are named "Request".
+	Name string
+	// Method and Path are the HTTP method and the path relative to the base
+	// URL, including any query string.
+	Method string
+	Path   string
+	// GuildID and UserID are the IDs the call is about, where it has them.
+	GuildID string
+	UserID  string
+	// Payload is the request body, if any.
+	Payload []byte
+	// Header holds extra headers to send with the request. Middleware may
+	// add to it; the Authorization and User-Agent headers are set by the
+	// client.
+	Header http.Header
+
+	// The fields below are filled in as the request is sent, for middleware
+	// to read after calling next.
+
+	// Attempts is the number of requests sent, including retries.
+	Attempts int
+	// StatusCode is the HTTP status of the last response, or 0 if none was
+	// received.
+	StatusCode int
+	// Bucket is the X-RateLimit-Bucket of the last response, if any.
+	Bucket string
+	// RateLimited is the number of responses that were 429 Too Many
+	// Requests, including any a retry got past.
+	RateLimited int
+	// RateLimitWait is the time spent holding requests back for the rate
+	// limiter.
+	RateLimitWait time.Duration
+	// RetryAfterWait is the time spent waiting out the retry-after of 429s
+	// before sending again.
+	RetryAfterWait time.Duration
+
+	// oneShot marks requests that are never retried after ambiguous
+	// failures, whatever their method.
+	oneShot bool
+}
+
+// Handler performs an operation and returns its decoded result, such as a
+// Balance for GetBalance or a []byte for Request, or nil if it has none.
+type Handler func(ctx context.Context, op *Operation) (interface{}, error)
+
+// Middleware wraps every call the client makes. It may inspect or change the
+// operation before calling next, and inspect or replace the result or error
+// after:
+//
+//	func audit(next v1.Handler) v1.Handler {
+//		return func(ctx context.Context, op *v1.Operation) (interface{}, error) {
+//			res, err := next(ctx, op)
+//			if bal, ok := res.(v1.Balance); ok && op.Name != "GetBalance" {
+//				log.Printf("%s changed %s to %s", op.Name, op.UserID, bal.Total)
+//			}
+//			return res, err
+//		}
+//	}
+//
+// A middleware that returns without calling next short-circuits the call; it
+// must then return a result of the type the endpoint returns, or nil.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware adds mw to the client's middleware chain. The first
+// middleware added is the outermost: it sees each operation first and its
+// result last.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *Client) error {
+		c.middleware = append(c.middleware, mw...)
+		return nil
+	}
+}
+
+type operationKey struct{}
+
+func withOperation(ctx context.Context, op *Operation) context.Context {
+	return context.WithValue(ctx, operationKey{}, op)
+}
+
+// operationFrom returns the operation a request is being made for, if any.
+func operationFrom(ctx context.Context) *Operation {
+	op, _ := ctx.Value(operationKey{}).(*Operation)
+	return op
+}
+
+// invoke runs op through the middleware chain, ending with h.
+func (u *Client) invoke(ctx context.Context, op *Operation, h Handler) (interface{}, error) {
+	if u.logger != nil {
+		h = u.logCall(h)
+	}
+	if u.metrics != nil {
+		h = u.measureCall(h)
+	}
+	for i := len(u.middleware) - 1; i >= 0; i-- {
+		h = u.middleware[i](h)
+	}
+	if u.tracer != nil {
+		// Outermost, so that middleware runs within the call's span.
+		h = u.traceCall(h)
+	}
+	return h(ctx, op)
+}
+
+// call runs op through the middleware chain, then sends it and decodes the
+// response with decode. A nil decode means the call has no result.
+func call[T any](ctx context.Context, u *Client, op *Operation, decode func(data []byte) (T, error)) (T, error) {
+	res, err := u.invoke(ctx, op, func(ctx context.Context, op *Operation) (interface{}, error) {
+		data, err := u.perform(ctx, op)
+		if err != nil || decode == nil {
+			return nil, err
+		}
+		return decode(data)
+	})
+	v, _ := res.(T)
+	return v, err
+}
+
+// perform sends op, retrying it as the retry policy allows.
+func (u *Client) perform(ctx context.Context, op *Operation) ([]byte, error) {
+	idempotent := isIdempotent(op.Method) && !op.oneShot
+	return u.request(withOperation(ctx, op), op.Method, op.Path, op.Payload, idempotent)
+}
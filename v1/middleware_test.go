@@ -0,0 +1,84 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddlewareSeesOperationAndResult(t *testing.T) {
+	var header string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Request-Id")
+		w.Write([]byte(testBalance))
+	}))
+	defer server.Close()
+
+	var order []string
+	var seen Operation
+	var result interface{}
+	trace := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, op *Operation) (interface{}, error) {
+				order = append(order, name+" in")
+				res, err := next(ctx, op)
+				order = append(order, name+" out")
+				return res, err
+			}
+		}
+	}
+	record := func(next Handler) Handler {
+		return func(ctx context.Context, op *Operation) (interface{}, error) {
+			op.Header = http.Header{"X-Request-Id": {"abc"}}
+			res, err := next(ctx, op)
+			seen, result = *op, res
+			return res, err
+		}
+	}
+	api, err := NewClient("token", WithBaseURL(server.URL), WithMiddleware(trace("outer"), trace("inner")), WithMiddleware(record))
+	ok(t, err)
+
+	bal, err := api.UpdateBalance("411898639737421824", "398197113495748626", 5, 0, "Payday")
+	ok(t, err)
+	equals(t, []string{"outer in", "inner in", "inner out", "outer out"}, order)
+	equals(t, "UpdateBalance", seen.Name)
+	equals(t, "PATCH", seen.Method)
+	equals(t, "/guilds/411898639737421824/users/398197113495748626", seen.Path)
+	equals(t, "411898639737421824", seen.GuildID)
+	equals(t, "398197113495748626", seen.UserID)
+	equals(t, `{"cash":5,"bank":0,"reason":"Payday"}`, string(seen.Payload))
+	equals(t, bal, result)
+	equals(t, "abc", header)
+}
+
+func TestMiddlewareSeesErrorsAndCanShortCircuit(t *testing.T) {
+	var seen error
+	observe := func(next Handler) Handler {
+		return func(ctx context.Context, op *Operation) (interface{}, error) {
+			res, err := next(ctx, op)
+			seen = err
+			return res, err
+		}
+	}
+	api, err := NewClient("token", WithHTTPClient(setClient(404, "", `{"error":"404: Not found","message":"Unknown item"}`)), WithMiddleware(observe))
+	ok(t, err)
+	_, err = api.GetItem("411898639737421824", "1")
+	equals(t, err, seen)
+
+	// A middleware can answer without sending anything.
+	stub := func(next Handler) Handler {
+		return func(ctx context.Context, op *Operation) (interface{}, error) {
+			if op.Name == "GetGuild" {
+				return Guild{ID: op.GuildID, Name: "Stub"}, nil
+			}
+			return nil, errors.New("unexpected call")
+		}
+	}
+	api, err = NewClient("token", WithHTTPClient(setHangingClient()), WithMiddleware(stub))
+	ok(t, err)
+	guild, err := api.GetGuild("411898639737421824")
+	ok(t, err)
+	equals(t, Guild{ID: "411898639737421824", Name: "Stub"}, guild)
+}
@@ -0,0 +1,101 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Permissions is the set of permissions a guild has granted the
+// application, as returned by GetPermissions.
+type Permissions uint64
+
+// Permissions a guild can grant an application.
+const (
+	// PermissionEconomyRead allows reading balances and the leaderboard.
+	PermissionEconomyRead Permissions = 1 << iota
+	// PermissionEconomyWrite allows setting and updating balances.
+	PermissionEconomyWrite
+	// PermissionItems allows managing the guild's store items.
+	PermissionItems
+	// PermissionInventory allows managing users' inventories.
+	PermissionInventory
+)
+
+var permissionNames = []struct {
+	p    Permissions
+	name string
+}{
+	{PermissionEconomyRead, "economy_read"},
+	{PermissionEconomyWrite, "economy_write"},
+	{PermissionItems, "items"},
+	{PermissionInventory, "inventory"},
+}
+
+// Has reports whether every permission in want is granted.
+func (p Permissions) Has(want Permissions) bool {
+	return p&want == want
+}
+
+// String lists the granted permissions, e.g. "economy_read|items".
+func (p Permissions) String() string {
+	var names []string
+	for _, n := range permissionNames {
+		if p&n.p != 0 {
+			names = append(names, n.name)
+			p &^= n.p
+		}
+	}
+	if p != 0 {
+		names = append(names, fmt.Sprintf("0x%x", uint64(p)))
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, "|")
+}
+
+// UnmarshalJSON decodes the bitfield from a number or a numeric string.
+func (p *Permissions) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("v1: invalid permissions %s", data)
+	}
+	*p = Permissions(n)
+	return nil
+}
+
+type permissionsResponse struct {
+	Permissions Permissions `json:"permissions"`
+}
+
+// GetPermissions fetches the permissions a guild has granted the
+// application, so that writes can be checked before they are attempted:
+//
+//	perms, err := api.GetPermissions(guildID)
+//	if err == nil && !perms.Has(v1.PermissionEconomyWrite) {
+//		// ask the guild to authorize the application
+//	}
+func (u *Client) GetPermissions(guild string) (Permissions, error) {
+	return u.GetPermissionsContext(context.Background(), guild)
+}
+
+// GetPermissionsContext is like GetPermissions but bound to ctx.
+func (u *Client) GetPermissionsContext(ctx context.Context, guild string) (Permissions, error) {
+	op := &Operation{Name: "GetPermissions", Method: "GET", Path: fmt.Sprintf("/applications/@me/guilds/%v", guild), GuildID: guild}
+	return call(ctx, u, op, func(data []byte) (Permissions, error) {
+		var resp permissionsResponse
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return 0, err
+		}
+		return resp.Permissions, nil
+	})
+}
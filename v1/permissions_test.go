@@ -0,0 +1,48 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestGetPermissionsHandlesDataOnSuccessfulFetchCorrectly(t *testing.T) {
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		equals(t, "https://unbelievable.pizza/api/v1/applications/@me/guilds/411898639737421824", req.URL.String())
+		return setClient(200, "", `{"permissions":5}`).Transport.(RoundTripFunc)(req)
+	})
+
+	api := Custom("token", client)
+	perms, err := api.GetPermissions("411898639737421824")
+	ok(t, err)
+	equals(t, PermissionEconomyRead|PermissionItems, perms)
+	equals(t, true, perms.Has(PermissionEconomyRead))
+	equals(t, false, perms.Has(PermissionEconomyWrite))
+	equals(t, false, perms.Has(PermissionEconomyRead|PermissionEconomyWrite))
+}
+
+func TestGetPermissionsAcceptsStringBitfield(t *testing.T) {
+	client := setClient(200, "/applications/@me/guilds/411898639737421824", `{"permissions":"15"}`)
+
+	api := Custom("token", client)
+	perms, err := api.GetPermissions("411898639737421824")
+	ok(t, err)
+	equals(t, true, perms.Has(PermissionEconomyRead|PermissionEconomyWrite|PermissionItems|PermissionInventory))
+}
+
+func TestGetPermissionsHandlesErrorOnUnauthorizedGuild(t *testing.T) {
+	client := setClient(403, "/applications/@me/guilds/000000000000000000", `{"error":"403: Forbidden","message":"Missing Access"}`)
+
+	api := Custom("token", client)
+	perms, err := api.GetPermissions("000000000000000000")
+	equals(t, Permissions(0), perms)
+	var apiErr *APIError
+	assert(t, errors.As(err, &apiErr), "expected *APIError, got %#v", err)
+	equals(t, 403, apiErr.StatusCode)
+}
+
+func TestPermissionsString(t *testing.T) {
+	equals(t, "none", Permissions(0).String())
+	equals(t, "economy_read|economy_write", (PermissionEconomyRead | PermissionEconomyWrite).String())
+	equals(t, "inventory|0x20", (PermissionInventory | 1<<5).String())
+}
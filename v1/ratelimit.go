@@ -0,0 +1,210 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BucketState is a snapshot of a rate limit bucket, as last reported by the
+// X-RateLimit-* headers of the API and adjusted for requests sent since.
+type BucketState struct {
+	// Key identifies the bucket within the client: the X-RateLimit-Bucket
+	// header (or the route, when the API didn't send one) and the guild the
+	// requests were for.
+	Key string
+	// Bucket is the X-RateLimit-Bucket header, if any.
+	Bucket string
+	// Limit is the number of requests allowed per window.
+	Limit int
+	// Remaining is the number of requests left in the current window.
+	Remaining int
+	// Reset is when the current window ends.
+	Reset time.Time
+}
+
+// rateLimiter holds requests back before they are sent once their bucket has
+// run out, instead of letting them come back as 429s. It is safe for
+// concurrent use.
+type rateLimiter struct {
+	mu      sync.Mutex
+	routes  map[string]string // route -> bucket key
+	buckets map[string]*BucketState
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		routes:  make(map[string]string),
+		buckets: make(map[string]*BucketState),
+	}
+}
+
+// wait blocks until a request on route may be sent, then reserves a slot in
+// its bucket. It returns how long it waited. If the wait would outlast ctx's
+// deadline, it returns a RateLimitError straight away.
+func (l *rateLimiter) wait(ctx context.Context, route string) (time.Duration, error) {
+	var waited time.Duration
+	for {
+		delay := l.reserve(route)
+		if delay <= 0 {
+			return waited, nil
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return waited, &RateLimitError{Message: "Rate limit bucket exhausted.", RetryAfter: delay}
+		}
+		if err := sleepContext(ctx, delay); err != nil {
+			return waited, err
+		}
+		waited += delay
+	}
+}
+
+// reserve takes a slot in route's bucket, or returns how long until one
+// frees up.
+func (l *rateLimiter) reserve(route string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.buckets[l.routes[route]]
+	if b == nil {
+		return 0
+	}
+	now := time.Now()
+	if !now.Before(b.Reset) {
+		if b.Limit <= 0 {
+			// Only a 429 told us about this bucket, and it has expired.
+			return 0
+		}
+		// The window has passed; assume a fresh one until told otherwise.
+		b.Remaining = b.Limit
+		b.Reset = now.Add(time.Second)
+	}
+	if b.Remaining <= 0 {
+		return b.Reset.Sub(now)
+	}
+	b.Remaining--
+	return 0
+}
+
+// update records the rate limit state reported by a response to a request
+// on route. err is the error parsed from the response, if any.
+func (l *rateLimiter) update(route string, h http.Header, err error) {
+	state, ok := parseRateLimitHeaders(h)
+	var rateErr *RateLimitError
+	limited := errors.As(err, &rateErr)
+	if !ok && !limited {
+		return
+	}
+	if limited {
+		state.Remaining = 0
+		if reset := time.Now().Add(rateErr.RetryAfter); reset.After(state.Reset) {
+			state.Reset = reset
+		}
+	}
+	state.Key = state.Bucket
+	if state.Key == "" {
+		state.Key = route
+	}
+	if guild := majorParam(route); guild != "" && state.Bucket != "" {
+		state.Key += " " + guild
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.routes[route] = state.Key
+	b := l.buckets[state.Key]
+	if b == nil {
+		l.buckets[state.Key] = &state
+		return
+	}
+	if state.Limit == 0 {
+		state.Limit = b.Limit
+	}
+	// Responses can arrive out of order; within a window, trust whichever
+	// count is lower, since in-flight requests have already been reserved.
+	// A reset worked out from X-RateLimit-Reset-After moves with the time
+	// the response arrived, so resets close together are the same window.
+	if !state.Reset.After(b.Reset.Add(resetTolerance)) && b.Remaining < state.Remaining {
+		state.Remaining = b.Remaining
+	}
+	*b = state
+}
+
+// snapshot returns a snapshot of all known buckets, sorted by key.
+func (l *rateLimiter) snapshot() []BucketState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	states := make([]BucketState, 0, len(l.buckets))
+	for _, b := range l.buckets {
+		states = append(states, *b)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Key < states[j].Key })
+	return states
+}
+
+// resetTolerance is how far apart two reported resets can be and still be
+// taken for the same window.
+const resetTolerance = time.Second
+
+func parseRateLimitHeaders(h http.Header) (BucketState, bool) {
+	var state BucketState
+	limit, err := strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return state, false
+	}
+	remaining, err := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return state, false
+	}
+	state.Limit, state.Remaining = limit, remaining
+	state.Bucket = h.Get("X-RateLimit-Bucket")
+	// Prefer the absolute reset: unlike X-RateLimit-Reset-After, it doesn't
+	// drift with how long the response took to arrive.
+	if reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		// The reset is a Unix timestamp, in milliseconds or seconds.
+		if reset > 1e12 {
+			state.Reset = time.Unix(0, reset*int64(time.Millisecond))
+		} else {
+			state.Reset = time.Unix(reset, 0)
+		}
+	} else if reset, err := strconv.ParseFloat(h.Get("X-RateLimit-Reset"), 64); err == nil {
+		state.Reset = time.Unix(0, int64(reset*float64(time.Second)))
+	} else if after, err := strconv.ParseFloat(h.Get("X-RateLimit-Reset-After"), 64); err == nil {
+		state.Reset = time.Now().Add(time.Duration(after * float64(time.Second)))
+	} else {
+		state.Reset = time.Now().Add(time.Second)
+	}
+	return state, true
+}
+
+// routeKey turns a request into the route it is rate limited on. The guild
+// ID is kept, since limits are applied per guild, while other IDs are
+// replaced so that, for example, every user's balance shares one route.
+func routeKey(method, url string) string {
+	if i := strings.IndexByte(url, '?'); i >= 0 {
+		url = url[:i]
+	}
+	parts := strings.Split(url, "/")
+	for i := 1; i < len(parts); i++ {
+		switch parts[i-1] {
+		case "users", "items", "inventory":
+			parts[i] = ":id"
+		}
+	}
+	return method + " " + strings.Join(parts, "/")
+}
+
+// majorParam returns the guild ID of a route, if it has one.
+func majorParam(route string) string {
+	parts := strings.Split(route, "/")
+	for i := 1; i < len(parts); i++ {
+		if parts[i-1] == "guilds" {
+			return parts[i]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,129 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Replies with a balance and rate limit headers saying the bucket is empty
+// until reset.
+func setRateLimitedClient(calls *int32, reset time.Time) *http.Client {
+	return NewTestClient(func(req *http.Request) *http.Response {
+		atomic.AddInt32(calls, 1)
+		header := make(http.Header)
+		header.Set("X-RateLimit-Limit", "1")
+		header.Set("X-RateLimit-Remaining", "0")
+		header.Set("X-RateLimit-Reset", strconv.FormatInt(reset.UnixNano()/int64(time.Millisecond), 10))
+		header.Set("X-RateLimit-Bucket", "balances")
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(testBalance)),
+			Header:     header,
+		}
+	})
+}
+
+func TestRateLimitsTracksBucketsFromHeaders(t *testing.T) {
+	var calls int32
+	reset := time.Now().Add(time.Minute).Truncate(time.Millisecond)
+	api := Custom("token", setRateLimitedClient(&calls, reset))
+
+	_, err := api.GetBalance("411898639737421824", "398197113495748626")
+	ok(t, err)
+	buckets := api.RateLimits()
+	equals(t, 1, len(buckets))
+	equals(t, "balances 411898639737421824", buckets[0].Key)
+	equals(t, "balances", buckets[0].Bucket)
+	equals(t, 1, buckets[0].Limit)
+	equals(t, 0, buckets[0].Remaining)
+	assert(t, buckets[0].Reset.Equal(reset), "expected reset %v, got %v", reset, buckets[0].Reset)
+}
+
+func TestRequestWaitsForExhaustedBucketBeforeSending(t *testing.T) {
+	var calls int32
+	api := Custom("token", setRateLimitedClient(&calls, time.Now().Add(50*time.Millisecond)))
+
+	_, err := api.GetBalance("411898639737421824", "398197113495748626")
+	ok(t, err)
+	start := time.Now()
+	// A different user shares the route, and so the bucket.
+	_, err = api.GetBalance("411898639737421824", "116293018742554625")
+	ok(t, err)
+	assert(t, time.Since(start) >= 30*time.Millisecond, "second request was sent after only %v", time.Since(start))
+	equals(t, int32(2), calls)
+}
+
+func TestRequestDoesNotWaitForOtherGuildsBuckets(t *testing.T) {
+	var calls int32
+	api := Custom("token", setRateLimitedClient(&calls, time.Now().Add(time.Minute)))
+
+	_, err := api.GetBalance("411898639737421824", "398197113495748626")
+	ok(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = api.GetBalanceContext(ctx, "000000000000000000", "398197113495748626")
+	ok(t, err)
+	equals(t, int32(2), calls)
+}
+
+func TestRequestFailsFastWhenBucketOutlastsDeadline(t *testing.T) {
+	var calls int32
+	api := Custom("token", setRateLimitedClient(&calls, time.Now().Add(time.Minute)))
+
+	_, err := api.GetBalance("411898639737421824", "398197113495748626")
+	ok(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = api.GetBalanceContext(ctx, "411898639737421824", "398197113495748626")
+	var rateErr *RateLimitError
+	assert(t, errors.As(err, &rateErr), "expected *RateLimitError, got %#v", err)
+	equals(t, int32(1), calls)
+}
+
+func TestRateLimitResponseEmptiesBucket(t *testing.T) {
+	var calls int32
+	api := Custom("token", setSequenceClient(&calls, cannedResponse{429, `{"message":"You are being rate limited.","retry_after":60000}`}))
+
+	_, err := api.GetBalance("411898639737421824", "398197113495748626")
+	assert(t, err != nil, "expected an error")
+	buckets := api.RateLimits()
+	equals(t, 1, len(buckets))
+	equals(t, "GET /guilds/411898639737421824/users/:id", buckets[0].Key)
+	equals(t, 0, buckets[0].Remaining)
+}
+
+func TestRateLimitsIgnoreStaleResponseWithResetAfter(t *testing.T) {
+	limiter := newRateLimiter()
+	headers := func(remaining string) http.Header {
+		header := make(http.Header)
+		header.Set("X-RateLimit-Limit", "10")
+		header.Set("X-RateLimit-Remaining", remaining)
+		header.Set("X-RateLimit-Reset-After", "1.0")
+		header.Set("X-RateLimit-Bucket", "balances")
+		return header
+	}
+	route := "GET /guilds/411898639737421824/users/:id"
+
+	// The later response arrives first, then an earlier one that was held
+	// up on the way.
+	limiter.update(route, headers("3"), nil)
+	time.Sleep(20 * time.Millisecond)
+	limiter.update(route, headers("5"), nil)
+	buckets := limiter.snapshot()
+	equals(t, 1, len(buckets))
+	equals(t, 3, buckets[0].Remaining)
+}
+
+func TestRouteKeyKeepsGuildAndReplacesOtherIDs(t *testing.T) {
+	equals(t, "GET /guilds/411898639737421824/users/:id", routeKey("GET", "/guilds/411898639737421824/users/398197113495748626"))
+	equals(t, "GET /guilds/411898639737421824/users", routeKey("GET", "/guilds/411898639737421824/users?page=2"))
+	equals(t, "411898639737421824", majorParam("GET /guilds/411898639737421824/users/:id"))
+	equals(t, "", majorParam("GET "))
+}
@@ -0,0 +1,115 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried. The zero value never
+// retries.
+//
+// Requests rejected with 429 Too Many Requests were never applied by the API,
+// so they are retried for every method after waiting for the retry-after the
+// API asked for. Server errors (5xx) and network failures are ambiguous, so
+// they are only retried for idempotent requests (GET, PUT and DELETE), after
+// a jittered exponential backoff. Requests such as UpdateBalance (PATCH) and
+// RemoveInventoryItem are never retried on ambiguous failures, since that
+// could apply them twice, unless they are ModifyBalance updates with an
+// IdempotencyKey.
+type RetryPolicy struct {
+	// MaxRetries is how many times a request may be re-sent after the first
+	// attempt.
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry of a failed request.
+	// It doubles on each subsequent retry. Defaults to 250ms.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff between retries. Defaults to 10s.
+	MaxDelay time.Duration
+	// MaxRetryAfter is the longest retry-after that will be waited out. If
+	// the API asks for a longer wait the RateLimitError is returned straight
+	// away. Zero means no limit.
+	MaxRetryAfter time.Duration
+}
+
+// DefaultRetryPolicy returns a policy suitable for most bots: up to three
+// retries with backoff starting at 250ms.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  250 * time.Millisecond,
+		MaxDelay:   10 * time.Second,
+	}
+}
+
+// next reports whether a request that failed with err on the given attempt
+// (0 being the first) should be sent again, and how long to wait first.
+func (p RetryPolicy) next(attempt int, idempotent bool, err error) (time.Duration, bool) {
+	if err == nil || attempt >= p.MaxRetries {
+		return 0, false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return 0, false
+	}
+	var rateErr *RateLimitError
+	if errors.As(err, &rateErr) {
+		if p.MaxRetryAfter > 0 && rateErr.RetryAfter > p.MaxRetryAfter {
+			return 0, false
+		}
+		if rateErr.RetryAfter > 0 {
+			return rateErr.RetryAfter, true
+		}
+		return p.backoff(attempt), true
+	}
+	if !idempotent {
+		return 0, false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode < 500 {
+		return 0, false
+	}
+	return p.backoff(attempt), true
+}
+
+// backoff returns the exponential backoff for attempt with "equal jitter":
+// a random duration between half and all of the capped delay.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	base, max := p.BaseDelay, p.MaxDelay
+	if base <= 0 {
+		base = 250 * time.Millisecond
+	}
+	if max <= 0 {
+		max = 10 * time.Second
+	}
+	d := base
+	for i := 0; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// sleepContext waits for d or until ctx is done, whichever comes first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,136 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type cannedResponse struct {
+	code int
+	data string
+}
+
+// Replies with each response in turn, repeating the last one, and counts calls.
+func setSequenceClient(calls *int32, responses ...cannedResponse) *http.Client {
+	return NewTestClient(func(req *http.Request) *http.Response {
+		n := int(atomic.AddInt32(calls, 1)) - 1
+		if n >= len(responses) {
+			n = len(responses) - 1
+		}
+		return &http.Response{
+			StatusCode: responses[n].code,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(responses[n].data)),
+			Header:     make(http.Header),
+		}
+	})
+}
+
+const testBalance = `{"user_id":"398197113495748626","cash":50,"bank":502,"total":552}`
+
+func TestRequestDoesNotRetryByDefault(t *testing.T) {
+	var calls int32
+	api := Custom("token", setSequenceClient(&calls, cannedResponse{429, `{"message":"You are being rate limited.","retry_after":1}`}, cannedResponse{200, testBalance}))
+
+	_, err := api.GetBalance("411898639737421824", "398197113495748626")
+	var rateErr *RateLimitError
+	assert(t, errors.As(err, &rateErr), "expected *RateLimitError, got %#v", err)
+	equals(t, int32(1), calls)
+}
+
+func TestRequestRetriesAfterRateLimit(t *testing.T) {
+	var calls int32
+	api, err := NewClient("token", WithHTTPClient(setSequenceClient(&calls, cannedResponse{429, `{"message":"You are being rate limited.","retry_after":1}`}, cannedResponse{200, testBalance})), WithRetryPolicy(RetryPolicy{MaxRetries: 2}))
+	ok(t, err)
+
+	data, err := api.UpdateBalance("411898639737421824", "398197113495748626", 10, 0, nil)
+	ok(t, err)
+	equals(t, NewAmount(552), data.Total)
+	equals(t, int32(2), calls)
+}
+
+func TestRequestGivesUpWhenRetryAfterTooLong(t *testing.T) {
+	var calls int32
+	api, err := NewClient("token", WithHTTPClient(setSequenceClient(&calls, cannedResponse{429, `{"message":"You are being rate limited.","retry_after":60000}`})), WithRetryPolicy(RetryPolicy{MaxRetries: 2, MaxRetryAfter: time.Second}))
+	ok(t, err)
+
+	_, err = api.GetBalance("411898639737421824", "398197113495748626")
+	var rateErr *RateLimitError
+	assert(t, errors.As(err, &rateErr), "expected *RateLimitError, got %#v", err)
+	equals(t, int32(1), calls)
+}
+
+func TestRequestRetriesIdempotentServerErrorsUpToLimit(t *testing.T) {
+	var calls int32
+	api, err := NewClient("token", WithHTTPClient(setSequenceClient(&calls, cannedResponse{503, ``})), WithRetryPolicy(RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond}))
+	ok(t, err)
+
+	_, err = api.GetBalance("411898639737421824", "398197113495748626")
+	var apiErr *APIError
+	assert(t, errors.As(err, &apiErr), "expected *APIError, got %#v", err)
+	equals(t, 503, apiErr.StatusCode)
+	equals(t, int32(3), calls)
+}
+
+func TestRequestDoesNotRetryPatchOnServerError(t *testing.T) {
+	var calls int32
+	api, err := NewClient("token", WithHTTPClient(setSequenceClient(&calls, cannedResponse{500, ``}, cannedResponse{200, testBalance})), WithRetryPolicy(RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond}))
+	ok(t, err)
+
+	_, err = api.UpdateBalance("411898639737421824", "398197113495748626", 10, 0, nil)
+	var apiErr *APIError
+	assert(t, errors.As(err, &apiErr), "expected *APIError, got %#v", err)
+	equals(t, int32(1), calls)
+}
+
+func TestRequestDoesNotRetryClientErrors(t *testing.T) {
+	var calls int32
+	api, err := NewClient("token", WithHTTPClient(setSequenceClient(&calls, cannedResponse{404, `{"error":"404: Not found","message":"Unknown guild"}`})), WithRetryPolicy(RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond}))
+	ok(t, err)
+
+	_, err = api.GetBalance("000000000000000000", "398197113495748626")
+	assert(t, err != nil, "expected an error")
+	equals(t, int32(1), calls)
+}
+
+func TestRequestStopsRetryingWhenContextIsDone(t *testing.T) {
+	var calls int32
+	api, err := NewClient("token", WithHTTPClient(setSequenceClient(&calls, cannedResponse{429, `{"message":"You are being rate limited.","retry_after":60000}`})), WithRetryPolicy(RetryPolicy{MaxRetries: 2}))
+	ok(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	_, err = api.GetBalanceContext(ctx, "411898639737421824", "398197113495748626")
+	equals(t, context.Canceled, err)
+	equals(t, int32(1), calls)
+}
+
+func TestRequestDoesNotWaitPastDeadline(t *testing.T) {
+	var calls int32
+	api, err := NewClient("token", WithHTTPClient(setSequenceClient(&calls, cannedResponse{429, `{"message":"You are being rate limited.","retry_after":60000}`})), WithRetryPolicy(RetryPolicy{MaxRetries: 2}))
+	ok(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	_, err = api.GetBalanceContext(ctx, "411898639737421824", "398197113495748626")
+	var rateErr *RateLimitError
+	assert(t, errors.As(err, &rateErr), "expected *RateLimitError, got %#v", err)
+	assert(t, time.Since(start) < 500*time.Millisecond, "waited %v for a retry that could not happen", time.Since(start))
+}
+
+func TestBackoffIsJitteredAndCapped(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for attempt, max := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		max *= time.Millisecond
+		for i := 0; i < 20; i++ {
+			d := p.backoff(attempt)
+			assert(t, d >= max/2 && d <= max, "attempt %d: backoff %v outside [%v, %v]", attempt, d, max/2, max)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package v1
+
+import "context"
+
+// Attribute is a key/value pair attached to a span. Value is a string, int,
+// int64, bool or float64.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Span is a unit of work started by a Tracer.
+type Span interface {
+	// SetAttributes adds attributes to the span.
+	SetAttributes(attrs ...Attribute)
+	// End finishes the span, marking it as failed if err is not nil.
+	End(err error)
+}
+
+// Tracer starts spans for the client, so that its calls show up in a
+// distributed trace. The unbotel package adapts an OpenTelemetry tracer.
+//
+// With a tracer set, the client starts a span named "unb.<Operation.Name>"
+// for every call, such as "unb.GetBalance", and a child span named
+// "unb.attempt" for each request sent for it, including retries. Both carry
+// the guild and user IDs and the HTTP method and status.
+type Tracer interface {
+	// Start begins a span as a child of any span in ctx, returning a
+	// context holding the new span.
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// WithTracer sets the tracer the client reports spans to. By default no
+// spans are started.
+func WithTracer(tracer Tracer) Option {
+	return func(c *Client) error {
+		c.tracer = tracer
+		return nil
+	}
+}
+
+// traceCall wraps h in a span for the whole call.
+func (u *Client) traceCall(h Handler) Handler {
+	return func(ctx context.Context, op *Operation) (interface{}, error) {
+		attrs := []Attribute{
+			{"unb.operation", op.Name},
+			{"http.request.method", op.Method},
+			{"unb.path", op.Path},
+		}
+		if op.GuildID != "" {
+			attrs = append(attrs, Attribute{"unb.guild_id", op.GuildID})
+		}
+		if op.UserID != "" {
+			attrs = append(attrs, Attribute{"unb.user_id", op.UserID})
+		}
+		ctx, span := u.tracer.Start(ctx, "unb."+op.Name, attrs...)
+		res, err := h(ctx, op)
+		attrs = []Attribute{{"unb.attempts", op.Attempts}}
+		if op.StatusCode != 0 {
+			attrs = append(attrs, Attribute{"http.response.status_code", op.StatusCode})
+		}
+		if op.Bucket != "" {
+			attrs = append(attrs, Attribute{"unb.rate_limit.bucket", op.Bucket})
+		}
+		if op.RateLimited > 0 {
+			attrs = append(attrs, Attribute{"unb.rate_limit.limited", op.RateLimited})
+		}
+		if op.RateLimitWait > 0 {
+			attrs = append(attrs, Attribute{"unb.rate_limit.wait_ms", op.RateLimitWait.Milliseconds()})
+		}
+		if op.RetryAfterWait > 0 {
+			attrs = append(attrs, Attribute{"unb.rate_limit.retry_after_wait_ms", op.RetryAfterWait.Milliseconds()})
+		}
+		span.SetAttributes(attrs...)
+		span.End(err)
+		return res, err
+	}
+}
+
+// startAttempt starts the span for one request of a call, if tracing.
+func (u *Client) startAttempt(ctx context.Context, attempt int, method, path string) (context.Context, Span) {
+	if u.tracer == nil {
+		return ctx, nil
+	}
+	attrs := []Attribute{
+		{"unb.attempt", attempt + 1},
+		{"http.request.method", method},
+		{"unb.path", path},
+	}
+	if op := operationFrom(ctx); op != nil {
+		if op.GuildID != "" {
+			attrs = append(attrs, Attribute{"unb.guild_id", op.GuildID})
+		}
+		if op.UserID != "" {
+			attrs = append(attrs, Attribute{"unb.user_id", op.UserID})
+		}
+	}
+	return u.tracer.Start(ctx, "unb.attempt", attrs...)
+}
+
+// endAttempt ends a span started by startAttempt.
+func endAttempt(ctx context.Context, span Span, err error) {
+	if span == nil {
+		return
+	}
+	if op := operationFrom(ctx); op != nil && op.StatusCode != 0 {
+		span.SetAttributes(Attribute{"http.response.status_code", op.StatusCode})
+	}
+	span.End(err)
+}
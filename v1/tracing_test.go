@@ -0,0 +1,94 @@
+package v1
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordedSpan struct {
+	name   string
+	parent string
+	attrs  map[string]interface{}
+	err    error
+	ended  bool
+}
+
+type spanKey struct{}
+
+// recordingTracer keeps every span it starts.
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := &recordedSpan{name: name, attrs: make(map[string]interface{})}
+	if parent, ok := ctx.Value(spanKey{}).(*recordedSpan); ok {
+		s.parent = parent.name
+	}
+	s.SetAttributes(attrs...)
+	t.spans = append(t.spans, s)
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...Attribute) {
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *recordedSpan) End(err error) {
+	s.err, s.ended = err, true
+}
+
+func TestWithTracerStartsSpansPerCallAndAttempt(t *testing.T) {
+	var calls int32
+	tracer := &recordingTracer{}
+	api, err := NewClient("token",
+		WithHTTPClient(setSequenceClient(&calls, cannedResponse{502, ``}, cannedResponse{200, testBalance})),
+		WithRetryPolicy(RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond}),
+		WithTracer(tracer),
+	)
+	ok(t, err)
+
+	_, err = api.GetBalance("411898639737421824", "398197113495748626")
+	ok(t, err)
+	equals(t, 3, len(tracer.spans))
+	call, first, second := tracer.spans[0], tracer.spans[1], tracer.spans[2]
+
+	equals(t, "unb.GetBalance", call.name)
+	equals(t, "411898639737421824", call.attrs["unb.guild_id"])
+	equals(t, "398197113495748626", call.attrs["unb.user_id"])
+	equals(t, 2, call.attrs["unb.attempts"])
+	equals(t, 200, call.attrs["http.response.status_code"])
+	equals(t, true, call.ended)
+	equals(t, nil, call.err)
+
+	equals(t, "unb.attempt", first.name)
+	equals(t, "unb.GetBalance", first.parent)
+	equals(t, 1, first.attrs["unb.attempt"])
+	equals(t, 502, first.attrs["http.response.status_code"])
+	assert(t, first.err != nil, "expected the first attempt to fail")
+	equals(t, 2, second.attrs["unb.attempt"])
+	equals(t, nil, second.err)
+}
+
+func TestWithTracerNamesLegacyBalanceCalls(t *testing.T) {
+	tracer := &recordingTracer{}
+	api, err := NewClient("token", WithHTTPClient(setClient(200, "", testBalance)), WithTracer(tracer))
+	ok(t, err)
+
+	_, err = api.SetBalance("411898639737421824", "398197113495748626", 5, nil, nil)
+	ok(t, err)
+	_, err = api.UpdateBalance("411898639737421824", "398197113495748626", 5, 0, nil)
+	ok(t, err)
+	_, err = api.Leaderboard("411898639737421824")
+	equals(t, "unb.SetBalance", tracer.spans[0].name)
+	equals(t, "unb.UpdateBalance", tracer.spans[2].name)
+	equals(t, "unb.Leaderboard", tracer.spans[4].name)
+	assert(t, tracer.spans[4].err != nil, "expected the leaderboard span to record the decode error")
+}
@@ -0,0 +1,64 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Balance is a user's balance in a guild, as returned by GetBalance,
+// SetBalance and UpdateBalance.
+type Balance struct {
+	// Rank is the user's position on the guild leaderboard, or 0 when the
+	// API didn't report it.
+	Rank int `json:"rank"`
+	// UserID is the Discord ID of the user.
+	UserID string `json:"user_id"`
+	Cash   Amount `json:"cash"`
+	Bank   Amount `json:"bank"`
+	// Total is cash plus bank, as computed by the API.
+	Total Amount `json:"total"`
+}
+
+// UnmarshalJSON decodes a balance, accepting the rank as either a number or
+// a string as the API sends both.
+func (b *Balance) UnmarshalJSON(data []byte) error {
+	type balance Balance
+	aux := struct {
+		Rank json.RawMessage `json:"rank"`
+		*balance
+	}{balance: (*balance)(b)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	b.Rank = 0
+	if len(aux.Rank) == 0 || string(aux.Rank) == "null" {
+		return nil
+	}
+	rank := string(aux.Rank)
+	if aux.Rank[0] == '"' {
+		if err := json.Unmarshal(aux.Rank, &rank); err != nil {
+			return err
+		}
+	}
+	n, err := strconv.Atoi(rank)
+	if err != nil {
+		return fmt.Errorf("v1: invalid rank %s", aux.Rank)
+	}
+	b.Rank = n
+	return nil
+}
+
+// LeaderboardEntry is one row of a guild leaderboard. Rank is always set.
+type LeaderboardEntry struct {
+	Balance
+}
+
+// HealthStatus is the result of Check.
+type HealthStatus struct {
+	// Ping is how long the API took to answer.
+	Ping time.Duration
+	// Up reports whether the API answered at all.
+	Up bool
+}
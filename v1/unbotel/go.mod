@@ -0,0 +1,23 @@
+module github.com/BaileyJM02/unb-api-go/v1/unbotel
+
+go 1.23.0
+
+require (
+	github.com/BaileyJM02/unb-api-go v0.0.0-20261017071538-872ddd7f0599
+	go.opentelemetry.io/otel v1.38.0
+	go.opentelemetry.io/otel/sdk v1.38.0
+	go.opentelemetry.io/otel/trace v1.38.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	go.opentelemetry.io/auto/sdk v1.1.0 // indirect
+	go.opentelemetry.io/otel/metric v1.38.0 // indirect
+	golang.org/x/sys v0.35.0 // indirect
+)
+
+// Build against the library in this repository. Go ignores replace
+// directives in dependencies, so users get the version required above.
+replace github.com/BaileyJM02/unb-api-go => ../..
@@ -0,0 +1,76 @@
+// Package unbotel reports the spans of a v1.Client to OpenTelemetry:
+//
+//	api, err := v1.NewClient(token, v1.WithTracer(unbotel.NewTracer(nil)))
+//
+// It is a separate module so that the v1 package itself doesn't depend on
+// OpenTelemetry.
+package unbotel
+
+import (
+	"context"
+	"fmt"
+
+	v1 "github.com/BaileyJM02/unb-api-go/v1"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName is the name the tracer is registered under.
+const InstrumentationName = "github.com/BaileyJM02/unb-api-go/v1"
+
+// NewTracer returns a v1.Tracer that starts client spans with a tracer from
+// tp, or from the global tracer provider if tp is nil.
+func NewTracer(tp trace.TracerProvider) v1.Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return tracer{tp.Tracer(InstrumentationName)}
+}
+
+type tracer struct {
+	t trace.Tracer
+}
+
+func (t tracer) Start(ctx context.Context, name string, attrs ...v1.Attribute) (context.Context, v1.Span) {
+	ctx, s := t.t.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(convert(attrs)...))
+	return ctx, span{s}
+}
+
+type span struct {
+	s trace.Span
+}
+
+func (s span) SetAttributes(attrs ...v1.Attribute) {
+	s.s.SetAttributes(convert(attrs)...)
+}
+
+func (s span) End(err error) {
+	if err != nil {
+		s.s.RecordError(err)
+		s.s.SetStatus(codes.Error, err.Error())
+	}
+	s.s.End()
+}
+
+func convert(attrs []v1.Attribute) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		switch v := a.Value.(type) {
+		case string:
+			kvs = append(kvs, attribute.String(a.Key, v))
+		case int:
+			kvs = append(kvs, attribute.Int(a.Key, v))
+		case int64:
+			kvs = append(kvs, attribute.Int64(a.Key, v))
+		case bool:
+			kvs = append(kvs, attribute.Bool(a.Key, v))
+		case float64:
+			kvs = append(kvs, attribute.Float64(a.Key, v))
+		default:
+			kvs = append(kvs, attribute.String(a.Key, fmt.Sprint(v)))
+		}
+	}
+	return kvs
+}
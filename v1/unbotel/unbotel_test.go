@@ -0,0 +1,65 @@
+package unbotel_test
+
+import (
+	"testing"
+
+	v1 "github.com/BaileyJM02/unb-api-go/v1"
+	"github.com/BaileyJM02/unb-api-go/v1/unbotel"
+	"github.com/BaileyJM02/unb-api-go/v1/unbtest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracerRecordsSpansForCalls(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	srv := unbtest.NewServer("token")
+	defer srv.Close()
+	srv.SetBalance("411898639737421824", "398197113495748626", v1.NewAmount(5), v1.NewAmount(0))
+	api, err := srv.NewClient(v1.WithTracer(unbotel.NewTracer(tp)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := api.GetBalance("411898639737421824", "398197113495748626"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.GetBalance("411898639737421824", "1"); err == nil {
+		t.Fatal("expected an error for an unknown user")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 4 {
+		t.Fatalf("expected 4 spans, got %d", len(spans))
+	}
+	attempt, call := spans[0], spans[1]
+	if call.Name() != "unb.GetBalance" || attempt.Name() != "unb.attempt" {
+		t.Fatalf("unexpected span names %q and %q", call.Name(), attempt.Name())
+	}
+	if call.SpanKind() != trace.SpanKindClient {
+		t.Errorf("expected a client span, got %v", call.SpanKind())
+	}
+	if attempt.Parent().SpanID() != call.SpanContext().SpanID() {
+		t.Error("expected the attempt to be a child of the call")
+	}
+	want := map[attribute.Key]attribute.Value{
+		"unb.guild_id":              attribute.StringValue("411898639737421824"),
+		"unb.user_id":               attribute.StringValue("398197113495748626"),
+		"http.response.status_code": attribute.IntValue(200),
+	}
+	for _, kv := range call.Attributes() {
+		if v, ok := want[kv.Key]; ok && v != kv.Value {
+			t.Errorf("%s: expected %v, got %v", kv.Key, v.Emit(), kv.Value.Emit())
+		}
+		delete(want, kv.Key)
+	}
+	if len(want) > 0 {
+		t.Errorf("missing attributes %v", want)
+	}
+	if failed := spans[3]; failed.Status().Code != codes.Error {
+		t.Errorf("expected an error status, got %v", failed.Status())
+	}
+}
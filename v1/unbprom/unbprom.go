@@ -0,0 +1,207 @@
+// Package unbprom collects the metrics of a v1.Client and serves them in the
+// Prometheus text exposition format, without depending on the Prometheus
+// client library:
+//
+//	collector := unbprom.NewCollector()
+//	api, err := v1.NewClient(token, v1.WithMetrics(collector))
+//	http.Handle("/metrics", collector)
+//
+// It exports:
+//
+//	unb_requests_total{operation,status}                  calls made, by final HTTP status or "error"
+//	unb_request_duration_seconds{operation}               histogram of call latency, retries included
+//	unb_retries_total{operation}                          requests re-sent after the first
+//	unb_rate_limited_total{operation}                     429 responses, including retried ones
+//	unb_rate_limit_wait_seconds_total{operation,reason}   time spent waiting on rate limits, for
+//	                                                      the client's limiter or a 429's retry-after
+//
+// Alert on unb_rate_limited_total rather than the 429s in unb_requests_total,
+// which only counts calls that still failed after retrying.
+//
+// A collector may be shared by several clients.
+package unbprom
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	v1 "github.com/BaileyJM02/unb-api-go/v1"
+)
+
+// DefaultBuckets are the latency histogram's upper bounds in seconds unless
+// others are given to NewCollector.
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Collector is a v1.Metrics that keeps counters and histograms in memory and
+// serves them over HTTP. Create one with NewCollector.
+type Collector struct {
+	buckets []float64
+
+	mu       sync.Mutex
+	requests map[requestKey]uint64
+	ops      map[string]*opStats
+}
+
+type requestKey struct {
+	operation, status string
+}
+
+type opStats struct {
+	counts         []uint64 // per bucket, not cumulative, plus +Inf
+	sum            float64
+	count          uint64
+	retries        uint64
+	rateLimited    uint64
+	rateLimitWait  float64
+	retryAfterWait float64
+}
+
+var _ v1.Metrics = (*Collector)(nil)
+
+// NewCollector returns an empty collector whose latency histogram has the
+// given upper bounds in seconds, or DefaultBuckets if none are given.
+func NewCollector(buckets ...float64) *Collector {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Collector{
+		buckets:  buckets,
+		requests: make(map[requestKey]uint64),
+		ops:      make(map[string]*opStats),
+	}
+}
+
+// ObserveCall implements v1.Metrics.
+func (c *Collector) ObserveCall(stats v1.CallStats) {
+	status := "error"
+	if stats.StatusCode != 0 {
+		status = strconv.Itoa(stats.StatusCode)
+	}
+	latency := stats.Latency.Seconds()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests[requestKey{stats.Operation, status}]++
+	s, ok := c.ops[stats.Operation]
+	if !ok {
+		s = &opStats{counts: make([]uint64, len(c.buckets)+1)}
+		c.ops[stats.Operation] = s
+	}
+	s.counts[sort.SearchFloat64s(c.buckets, latency)]++
+	s.sum += latency
+	s.count++
+	s.retries += uint64(stats.Retries)
+	s.rateLimited += uint64(stats.RateLimited)
+	s.rateLimitWait += stats.RateLimitWait.Seconds()
+	s.retryAfterWait += stats.RetryAfterWait.Seconds()
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.WriteTo(w)
+}
+
+// WriteTo writes the metrics to w in the Prometheus text exposition format.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	c.write(bw)
+	err := bw.Flush()
+	return cw.n, err
+}
+
+func (c *Collector) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys := make([]requestKey, 0, len(c.requests))
+	for k := range c.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].operation != keys[j].operation {
+			return keys[i].operation < keys[j].operation
+		}
+		return keys[i].status < keys[j].status
+	})
+	ops := make([]string, 0, len(c.ops))
+	for op := range c.ops {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+
+	fmt.Fprint(w, "# HELP unb_requests_total UnbelievaBoat API calls made, by operation and HTTP status.\n")
+	fmt.Fprint(w, "# TYPE unb_requests_total counter\n")
+	for _, k := range keys {
+		fmt.Fprintf(w, "unb_requests_total{operation=%s,status=%s} %d\n", quote(k.operation), quote(k.status), c.requests[k])
+	}
+
+	fmt.Fprint(w, "# HELP unb_request_duration_seconds Latency of UnbelievaBoat API calls, including retries.\n")
+	fmt.Fprint(w, "# TYPE unb_request_duration_seconds histogram\n")
+	for _, op := range ops {
+		s := c.ops[op]
+		var cumulative uint64
+		for i, le := range c.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(w, "unb_request_duration_seconds_bucket{operation=%s,le=%s} %d\n", quote(op), quote(formatFloat(le)), cumulative)
+		}
+		fmt.Fprintf(w, "unb_request_duration_seconds_bucket{operation=%s,le=\"+Inf\"} %d\n", quote(op), s.count)
+		fmt.Fprintf(w, "unb_request_duration_seconds_sum{operation=%s} %s\n", quote(op), formatFloat(s.sum))
+		fmt.Fprintf(w, "unb_request_duration_seconds_count{operation=%s} %d\n", quote(op), s.count)
+	}
+
+	fmt.Fprint(w, "# HELP unb_retries_total UnbelievaBoat API requests re-sent after the first attempt.\n")
+	fmt.Fprint(w, "# TYPE unb_retries_total counter\n")
+	for _, op := range ops {
+		fmt.Fprintf(w, "unb_retries_total{operation=%s} %d\n", quote(op), c.ops[op].retries)
+	}
+
+	fmt.Fprint(w, "# HELP unb_rate_limited_total UnbelievaBoat API responses that were 429 Too Many Requests, including retried ones.\n")
+	fmt.Fprint(w, "# TYPE unb_rate_limited_total counter\n")
+	for _, op := range ops {
+		fmt.Fprintf(w, "unb_rate_limited_total{operation=%s} %d\n", quote(op), c.ops[op].rateLimited)
+	}
+
+	fmt.Fprint(w, "# HELP unb_rate_limit_wait_seconds_total Time spent waiting on UnbelievaBoat rate limits, by the client's limiter or a 429's retry-after.\n")
+	fmt.Fprint(w, "# TYPE unb_rate_limit_wait_seconds_total counter\n")
+	for _, op := range ops {
+		s := c.ops[op]
+		fmt.Fprintf(w, "unb_rate_limit_wait_seconds_total{operation=%s,reason=\"limiter\"} %s\n", quote(op), formatFloat(s.rateLimitWait))
+		fmt.Fprintf(w, "unb_rate_limit_wait_seconds_total{operation=%s,reason=\"retry_after\"} %s\n", quote(op), formatFloat(s.retryAfterWait))
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// quote formats a label value.
+func quote(s string) string {
+	return `"` + labelEscaper.Replace(s) + `"`
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,116 @@
+package unbprom_test
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/BaileyJM02/unb-api-go/v1"
+	"github.com/BaileyJM02/unb-api-go/v1/unbprom"
+	"github.com/BaileyJM02/unb-api-go/v1/unbtest"
+)
+
+func TestCollectorExposesCalls(t *testing.T) {
+	c := unbprom.NewCollector(0.1, 1)
+	c.ObserveCall(v1.CallStats{Operation: "GetBalance", StatusCode: 200, Latency: 50 * time.Millisecond})
+	c.ObserveCall(v1.CallStats{Operation: "GetBalance", StatusCode: 200, Latency: 500 * time.Millisecond, Retries: 1, RateLimitWait: 250 * time.Millisecond})
+	c.ObserveCall(v1.CallStats{Operation: "GetBalance", StatusCode: 429, Latency: 2 * time.Second, Retries: 2, RateLimited: 3, RetryAfterWait: 1500 * time.Millisecond})
+	c.ObserveCall(v1.CallStats{Operation: "SetBalance", Err: errors.New("timeout"), Latency: time.Second})
+
+	server := httptest.NewServer(c)
+	defer server.Close()
+	resp, err := server.Client().Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{
+		"# TYPE unb_requests_total counter",
+		`unb_requests_total{operation="GetBalance",status="200"} 2`,
+		`unb_requests_total{operation="GetBalance",status="429"} 1`,
+		`unb_requests_total{operation="SetBalance",status="error"} 1`,
+		"# TYPE unb_request_duration_seconds histogram",
+		`unb_request_duration_seconds_bucket{operation="GetBalance",le="0.1"} 1`,
+		`unb_request_duration_seconds_bucket{operation="GetBalance",le="1"} 2`,
+		`unb_request_duration_seconds_bucket{operation="GetBalance",le="+Inf"} 3`,
+		`unb_request_duration_seconds_sum{operation="GetBalance"} 2.55`,
+		`unb_request_duration_seconds_count{operation="GetBalance"} 3`,
+		`unb_request_duration_seconds_bucket{operation="SetBalance",le="1"} 1`,
+		`unb_retries_total{operation="GetBalance"} 3`,
+		`unb_rate_limited_total{operation="GetBalance"} 3`,
+		`unb_rate_limited_total{operation="SetBalance"} 0`,
+		`unb_rate_limit_wait_seconds_total{operation="GetBalance",reason="limiter"} 0.25`,
+		`unb_rate_limit_wait_seconds_total{operation="GetBalance",reason="retry_after"} 1.5`,
+		`unb_rate_limit_wait_seconds_total{operation="SetBalance",reason="limiter"} 0`,
+	} {
+		if !strings.Contains(string(body), line+"\n") {
+			t.Errorf("missing %q in:\n%s", line, body)
+		}
+	}
+}
+
+func TestCollectorMeasuresClient(t *testing.T) {
+	srv := unbtest.NewServer("token")
+	defer srv.Close()
+	c := unbprom.NewCollector()
+	api, err := srv.NewClient(v1.WithMetrics(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.FailNext(403, "Missing Permissions")
+	api.GetGuild("411898639737421824")
+	api.GetGuild("411898639737421824")
+
+	var b strings.Builder
+	if _, err := c.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`unb_requests_total{operation="GetGuild",status="200"} 1`,
+		`unb_requests_total{operation="GetGuild",status="403"} 1`,
+		`unb_request_duration_seconds_count{operation="GetGuild"} 2`,
+	} {
+		if !strings.Contains(b.String(), line+"\n") {
+			t.Errorf("missing %q in:\n%s", line, b.String())
+		}
+	}
+}
+
+func TestCollectorCountsRetried429s(t *testing.T) {
+	srv := unbtest.NewServer("token")
+	defer srv.Close()
+	c := unbprom.NewCollector()
+	api, err := srv.NewClient(v1.WithMetrics(c), v1.WithRetryPolicy(v1.RetryPolicy{MaxRetries: 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.RateLimitNext(time.Millisecond)
+	if _, err := api.GetGuild("411898639737421824"); err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	if _, err := c.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`unb_requests_total{operation="GetGuild",status="200"} 1`,
+		`unb_retries_total{operation="GetGuild"} 1`,
+		`unb_rate_limited_total{operation="GetGuild"} 1`,
+	} {
+		if !strings.Contains(b.String(), line+"\n") {
+			t.Errorf("missing %q in:\n%s", line, b.String())
+		}
+	}
+}
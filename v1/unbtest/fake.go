@@ -0,0 +1,350 @@
+package unbtest
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	v1 "github.com/BaileyJM02/unb-api-go/v1"
+)
+
+// ErrNotStubbed is returned by Fake methods whose Func field is nil.
+var ErrNotStubbed = errors.New("unbtest: method not stubbed")
+
+// Call is a call made to a Fake.
+type Call struct {
+	// Method is the name of the method without its Context suffix, e.g.
+	// "GetBalance" for both GetBalance and GetBalanceContext.
+	Method string
+	// Args are the arguments after the context.
+	Args []interface{}
+}
+
+// Fake is a v1.API that records its calls and answers them with the
+// matching Func field, for unit tests that don't need HTTP at all:
+//
+//	fake := &unbtest.Fake{
+//		GetBalanceFunc: func(ctx context.Context, guild, user string) (v1.Balance, error) {
+//			return v1.Balance{UserID: user, Cash: v1.NewAmount(100)}, nil
+//		},
+//	}
+//	handle(fake)
+//	calls := fake.CallsTo("GetBalance")
+//
+// Methods whose Func is nil return ErrNotStubbed. The Iterate methods page
+// through ListItemsFunc, ListInventoryFunc and GetLeaderboardPageFunc. Set
+// the Func fields before use; the Fake is then safe for concurrent use.
+type Fake struct {
+	CheckFunc               func(ctx context.Context) (v1.HealthStatus, error)
+	GetBalanceFunc          func(ctx context.Context, guild, user string) (v1.Balance, error)
+	SetBalanceFunc          func(ctx context.Context, guild, user string, cash, bank, reason interface{}) (v1.Balance, error)
+	UpdateBalanceFunc       func(ctx context.Context, guild, user string, cash, bank int, reason interface{}) (v1.Balance, error)
+	ModifyBalanceFunc       func(ctx context.Context, guild, user string, update *v1.BalanceUpdate) (v1.Balance, error)
+	LeaderboardFunc         func(ctx context.Context, guild string) ([]v1.LeaderboardEntry, error)
+	GetLeaderboardPageFunc  func(ctx context.Context, guild string, opts v1.LeaderboardOptions) (v1.LeaderboardPage, error)
+	GetGuildFunc            func(ctx context.Context, guild string) (v1.Guild, error)
+	GetPermissionsFunc      func(ctx context.Context, guild string) (v1.Permissions, error)
+	ListItemsFunc           func(ctx context.Context, guild string, opts v1.ListOptions) (v1.ItemPage, error)
+	GetItemFunc             func(ctx context.Context, guild, item string) (v1.Item, error)
+	CreateItemFunc          func(ctx context.Context, guild string, item v1.Item) (v1.Item, error)
+	UpdateItemFunc          func(ctx context.Context, guild, item string, update v1.ItemUpdate) (v1.Item, error)
+	DeleteItemFunc          func(ctx context.Context, guild, item string) error
+	ListInventoryFunc       func(ctx context.Context, guild, user string, opts v1.ListOptions) (v1.InventoryPage, error)
+	GetInventoryItemFunc    func(ctx context.Context, guild, user, item string) (v1.InventoryItem, error)
+	AddInventoryItemFunc    func(ctx context.Context, guild, user, item string, quantity int) (v1.InventoryItem, error)
+	RemoveInventoryItemFunc func(ctx context.Context, guild, user, item string, quantity int) (v1.InventoryItem, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ v1.API = (*Fake)(nil)
+
+// Calls returns every call made so far, in order.
+func (f *Fake) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// CallsTo returns the calls made to method, e.g. "GetBalance".
+func (f *Fake) CallsTo(method string) []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var calls []Call
+	for _, c := range f.calls {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Reset forgets the calls made so far.
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = nil
+}
+
+func (f *Fake) record(method string, args ...interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, Call{Method: method, Args: args})
+}
+
+// The methods below implement v1.API by recording the call and then calling
+// the matching Func field.
+
+func (f *Fake) Check() (v1.HealthStatus, error) {
+	return f.CheckContext(context.Background())
+}
+
+func (f *Fake) CheckContext(ctx context.Context) (v1.HealthStatus, error) {
+	f.record("Check")
+	if f.CheckFunc == nil {
+		return v1.HealthStatus{}, ErrNotStubbed
+	}
+	return f.CheckFunc(ctx)
+}
+
+func (f *Fake) GetBalance(guild, user string) (v1.Balance, error) {
+	return f.GetBalanceContext(context.Background(), guild, user)
+}
+
+func (f *Fake) GetBalanceContext(ctx context.Context, guild, user string) (v1.Balance, error) {
+	f.record("GetBalance", guild, user)
+	if f.GetBalanceFunc == nil {
+		return v1.Balance{}, ErrNotStubbed
+	}
+	return f.GetBalanceFunc(ctx, guild, user)
+}
+
+func (f *Fake) SetBalance(guild, user string, cash, bank, reason interface{}) (v1.Balance, error) {
+	return f.SetBalanceContext(context.Background(), guild, user, cash, bank, reason)
+}
+
+func (f *Fake) SetBalanceContext(ctx context.Context, guild, user string, cash, bank, reason interface{}) (v1.Balance, error) {
+	f.record("SetBalance", guild, user, cash, bank, reason)
+	if f.SetBalanceFunc == nil {
+		return v1.Balance{}, ErrNotStubbed
+	}
+	return f.SetBalanceFunc(ctx, guild, user, cash, bank, reason)
+}
+
+func (f *Fake) UpdateBalance(guild, user string, cash, bank int, reason interface{}) (v1.Balance, error) {
+	return f.UpdateBalanceContext(context.Background(), guild, user, cash, bank, reason)
+}
+
+func (f *Fake) UpdateBalanceContext(ctx context.Context, guild, user string, cash, bank int, reason interface{}) (v1.Balance, error) {
+	f.record("UpdateBalance", guild, user, cash, bank, reason)
+	if f.UpdateBalanceFunc == nil {
+		return v1.Balance{}, ErrNotStubbed
+	}
+	return f.UpdateBalanceFunc(ctx, guild, user, cash, bank, reason)
+}
+
+func (f *Fake) ModifyBalance(guild, user string, update *v1.BalanceUpdate) (v1.Balance, error) {
+	return f.ModifyBalanceContext(context.Background(), guild, user, update)
+}
+
+func (f *Fake) ModifyBalanceContext(ctx context.Context, guild, user string, update *v1.BalanceUpdate) (v1.Balance, error) {
+	f.record("ModifyBalance", guild, user, update)
+	if f.ModifyBalanceFunc == nil {
+		return v1.Balance{}, ErrNotStubbed
+	}
+	return f.ModifyBalanceFunc(ctx, guild, user, update)
+}
+
+func (f *Fake) Leaderboard(guild string) ([]v1.LeaderboardEntry, error) {
+	return f.LeaderboardContext(context.Background(), guild)
+}
+
+func (f *Fake) LeaderboardContext(ctx context.Context, guild string) ([]v1.LeaderboardEntry, error) {
+	f.record("Leaderboard", guild)
+	if f.LeaderboardFunc == nil {
+		return nil, ErrNotStubbed
+	}
+	return f.LeaderboardFunc(ctx, guild)
+}
+
+func (f *Fake) GetLeaderboardPage(guild string, opts v1.LeaderboardOptions) (v1.LeaderboardPage, error) {
+	return f.GetLeaderboardPageContext(context.Background(), guild, opts)
+}
+
+func (f *Fake) GetLeaderboardPageContext(ctx context.Context, guild string, opts v1.LeaderboardOptions) (v1.LeaderboardPage, error) {
+	f.record("GetLeaderboardPage", guild, opts)
+	if f.GetLeaderboardPageFunc == nil {
+		return v1.LeaderboardPage{}, ErrNotStubbed
+	}
+	return f.GetLeaderboardPageFunc(ctx, guild, opts)
+}
+
+func (f *Fake) IterateLeaderboard(guild string, opts v1.LeaderboardOptions) *v1.Iterator[v1.LeaderboardEntry] {
+	return f.IterateLeaderboardContext(context.Background(), guild, opts)
+}
+
+func (f *Fake) IterateLeaderboardContext(ctx context.Context, guild string, opts v1.LeaderboardOptions) *v1.Iterator[v1.LeaderboardEntry] {
+	f.record("IterateLeaderboard", guild, opts)
+	return v1.NewIterator(ctx, v1.ListOptions{Limit: opts.Limit, Page: opts.Page}, func(ctx context.Context, lo v1.ListOptions) ([]v1.LeaderboardEntry, int, error) {
+		opts.Page = lo.Page
+		page, err := f.GetLeaderboardPageContext(ctx, guild, opts)
+		return page.Entries, page.TotalPages, err
+	})
+}
+
+func (f *Fake) GetGuild(guild string) (v1.Guild, error) {
+	return f.GetGuildContext(context.Background(), guild)
+}
+
+func (f *Fake) GetGuildContext(ctx context.Context, guild string) (v1.Guild, error) {
+	f.record("GetGuild", guild)
+	if f.GetGuildFunc == nil {
+		return v1.Guild{}, ErrNotStubbed
+	}
+	return f.GetGuildFunc(ctx, guild)
+}
+
+func (f *Fake) GetPermissions(guild string) (v1.Permissions, error) {
+	return f.GetPermissionsContext(context.Background(), guild)
+}
+
+func (f *Fake) GetPermissionsContext(ctx context.Context, guild string) (v1.Permissions, error) {
+	f.record("GetPermissions", guild)
+	if f.GetPermissionsFunc == nil {
+		return 0, ErrNotStubbed
+	}
+	return f.GetPermissionsFunc(ctx, guild)
+}
+
+func (f *Fake) ListItems(guild string, opts v1.ListOptions) (v1.ItemPage, error) {
+	return f.ListItemsContext(context.Background(), guild, opts)
+}
+
+func (f *Fake) ListItemsContext(ctx context.Context, guild string, opts v1.ListOptions) (v1.ItemPage, error) {
+	f.record("ListItems", guild, opts)
+	if f.ListItemsFunc == nil {
+		return v1.ItemPage{}, ErrNotStubbed
+	}
+	return f.ListItemsFunc(ctx, guild, opts)
+}
+
+func (f *Fake) IterateItems(guild string, opts v1.ListOptions) *v1.Iterator[v1.Item] {
+	return f.IterateItemsContext(context.Background(), guild, opts)
+}
+
+func (f *Fake) IterateItemsContext(ctx context.Context, guild string, opts v1.ListOptions) *v1.Iterator[v1.Item] {
+	f.record("IterateItems", guild, opts)
+	return v1.NewIterator(ctx, opts, func(ctx context.Context, opts v1.ListOptions) ([]v1.Item, int, error) {
+		page, err := f.ListItemsContext(ctx, guild, opts)
+		return page.Items, page.TotalPages, err
+	})
+}
+
+func (f *Fake) GetItem(guild, item string) (v1.Item, error) {
+	return f.GetItemContext(context.Background(), guild, item)
+}
+
+func (f *Fake) GetItemContext(ctx context.Context, guild, item string) (v1.Item, error) {
+	f.record("GetItem", guild, item)
+	if f.GetItemFunc == nil {
+		return v1.Item{}, ErrNotStubbed
+	}
+	return f.GetItemFunc(ctx, guild, item)
+}
+
+func (f *Fake) CreateItem(guild string, item v1.Item) (v1.Item, error) {
+	return f.CreateItemContext(context.Background(), guild, item)
+}
+
+func (f *Fake) CreateItemContext(ctx context.Context, guild string, item v1.Item) (v1.Item, error) {
+	f.record("CreateItem", guild, item)
+	if f.CreateItemFunc == nil {
+		return v1.Item{}, ErrNotStubbed
+	}
+	return f.CreateItemFunc(ctx, guild, item)
+}
+
+func (f *Fake) UpdateItem(guild, item string, update v1.ItemUpdate) (v1.Item, error) {
+	return f.UpdateItemContext(context.Background(), guild, item, update)
+}
+
+func (f *Fake) UpdateItemContext(ctx context.Context, guild, item string, update v1.ItemUpdate) (v1.Item, error) {
+	f.record("UpdateItem", guild, item, update)
+	if f.UpdateItemFunc == nil {
+		return v1.Item{}, ErrNotStubbed
+	}
+	return f.UpdateItemFunc(ctx, guild, item, update)
+}
+
+func (f *Fake) DeleteItem(guild, item string) error {
+	return f.DeleteItemContext(context.Background(), guild, item)
+}
+
+func (f *Fake) DeleteItemContext(ctx context.Context, guild, item string) error {
+	f.record("DeleteItem", guild, item)
+	if f.DeleteItemFunc == nil {
+		return ErrNotStubbed
+	}
+	return f.DeleteItemFunc(ctx, guild, item)
+}
+
+func (f *Fake) ListInventory(guild, user string, opts v1.ListOptions) (v1.InventoryPage, error) {
+	return f.ListInventoryContext(context.Background(), guild, user, opts)
+}
+
+func (f *Fake) ListInventoryContext(ctx context.Context, guild, user string, opts v1.ListOptions) (v1.InventoryPage, error) {
+	f.record("ListInventory", guild, user, opts)
+	if f.ListInventoryFunc == nil {
+		return v1.InventoryPage{}, ErrNotStubbed
+	}
+	return f.ListInventoryFunc(ctx, guild, user, opts)
+}
+
+func (f *Fake) IterateInventory(guild, user string, opts v1.ListOptions) *v1.Iterator[v1.InventoryItem] {
+	return f.IterateInventoryContext(context.Background(), guild, user, opts)
+}
+
+func (f *Fake) IterateInventoryContext(ctx context.Context, guild, user string, opts v1.ListOptions) *v1.Iterator[v1.InventoryItem] {
+	f.record("IterateInventory", guild, user, opts)
+	return v1.NewIterator(ctx, opts, func(ctx context.Context, opts v1.ListOptions) ([]v1.InventoryItem, int, error) {
+		page, err := f.ListInventoryContext(ctx, guild, user, opts)
+		return page.Items, page.TotalPages, err
+	})
+}
+
+func (f *Fake) GetInventoryItem(guild, user, item string) (v1.InventoryItem, error) {
+	return f.GetInventoryItemContext(context.Background(), guild, user, item)
+}
+
+func (f *Fake) GetInventoryItemContext(ctx context.Context, guild, user, item string) (v1.InventoryItem, error) {
+	f.record("GetInventoryItem", guild, user, item)
+	if f.GetInventoryItemFunc == nil {
+		return v1.InventoryItem{}, ErrNotStubbed
+	}
+	return f.GetInventoryItemFunc(ctx, guild, user, item)
+}
+
+func (f *Fake) AddInventoryItem(guild, user, item string, quantity int) (v1.InventoryItem, error) {
+	return f.AddInventoryItemContext(context.Background(), guild, user, item, quantity)
+}
+
+func (f *Fake) AddInventoryItemContext(ctx context.Context, guild, user, item string, quantity int) (v1.InventoryItem, error) {
+	f.record("AddInventoryItem", guild, user, item, quantity)
+	if f.AddInventoryItemFunc == nil {
+		return v1.InventoryItem{}, ErrNotStubbed
+	}
+	return f.AddInventoryItemFunc(ctx, guild, user, item, quantity)
+}
+
+func (f *Fake) RemoveInventoryItem(guild, user, item string, quantity int) (v1.InventoryItem, error) {
+	return f.RemoveInventoryItemContext(context.Background(), guild, user, item, quantity)
+}
+
+func (f *Fake) RemoveInventoryItemContext(ctx context.Context, guild, user, item string, quantity int) (v1.InventoryItem, error) {
+	f.record("RemoveInventoryItem", guild, user, item, quantity)
+	if f.RemoveInventoryItemFunc == nil {
+		return v1.InventoryItem{}, ErrNotStubbed
+	}
+	return f.RemoveInventoryItemFunc(ctx, guild, user, item, quantity)
+}
@@ -0,0 +1,67 @@
+package unbtest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	v1 "github.com/BaileyJM02/unb-api-go/v1"
+	"github.com/BaileyJM02/unb-api-go/v1/unbtest"
+)
+
+// payday stands in for consumer code that only depends on v1.API.
+func payday(api v1.API, guild, user string) (v1.Amount, error) {
+	bal, err := api.UpdateBalance(guild, user, 100, 0, "Payday")
+	return bal.Cash, err
+}
+
+func TestFakeRecordsCallsAndReturnsStubs(t *testing.T) {
+	fake := &unbtest.Fake{
+		UpdateBalanceFunc: func(ctx context.Context, guild, user string, cash, bank int, reason interface{}) (v1.Balance, error) {
+			return v1.Balance{UserID: user, Cash: v1.NewAmount(int64(cash))}, nil
+		},
+	}
+
+	cash, err := payday(fake, guildID, userID)
+	ok(t, err)
+	equals(t, v1.NewAmount(100), cash)
+	equals(t, []unbtest.Call{{Method: "UpdateBalance", Args: []interface{}{guildID, userID, 100, 0, "Payday"}}}, fake.Calls())
+
+	// Context variants are recorded under the same name.
+	_, err = fake.GetBalanceContext(context.Background(), guildID, userID)
+	equals(t, unbtest.ErrNotStubbed, err)
+	equals(t, 1, len(fake.CallsTo("GetBalance")))
+
+	fake.Reset()
+	equals(t, 0, len(fake.Calls()))
+}
+
+func TestFakeIteratesThroughListStub(t *testing.T) {
+	fake := &unbtest.Fake{
+		ListItemsFunc: func(ctx context.Context, guild string, opts v1.ListOptions) (v1.ItemPage, error) {
+			if opts.Page == 3 {
+				return v1.ItemPage{}, errors.New("boom")
+			}
+			return v1.ItemPage{Items: []v1.Item{{Name: "Pizza"}}, TotalPages: 5}, nil
+		},
+	}
+
+	it := fake.IterateItems(guildID, v1.ListOptions{Limit: 1})
+	n := 0
+	for it.Next() {
+		n++
+	}
+	equals(t, 2, n)
+	equals(t, "boom", it.Err().Error())
+	equals(t, 3, len(fake.CallsTo("ListItems")))
+	equals(t, 1, len(fake.CallsTo("IterateItems")))
+}
+
+func TestServerClientSatisfiesAPI(t *testing.T) {
+	srv, api := newClient(t)
+	srv.SetBalance(guildID, userID, v1.NewAmount(5), v1.NewAmount(0))
+
+	cash, err := payday(api, guildID, userID)
+	ok(t, err)
+	equals(t, v1.NewAmount(105), cash)
+}
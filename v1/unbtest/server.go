@@ -0,0 +1,658 @@
+// Package unbtest provides an in-memory fake of the UnbelievaBoat API, so
+// that code using the v1 client can be tested end to end without network
+// access:
+//
+//	srv := unbtest.NewServer("token")
+//	defer srv.Close()
+//	srv.SetBalance(guildID, userID, v1.NewAmount(100), v1.NewAmount(0))
+//
+//	api, err := srv.NewClient()
+//	bal, err := api.UpdateBalance(guildID, userID, 50, 0, "Payday")
+//
+// The fake implements balances, the leaderboard, guilds, application
+// permissions, store items and inventories, checks the token and
+// permissions the way the API does, and can be told to fail or rate limit
+// requests.
+package unbtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "github.com/BaileyJM02/unb-api-go/v1"
+)
+
+// AllPermissions is granted to every guild unless SetPermissions is used.
+const AllPermissions = v1.PermissionEconomyRead | v1.PermissionEconomyWrite | v1.PermissionItems | v1.PermissionInventory
+
+// Request is a request received by the Server.
+type Request struct {
+	Method string
+	// Path includes the query string, if any.
+	Path string
+	Body []byte
+}
+
+// Server is a fake UnbelievaBoat API listening on a local port. Guilds and
+// users are created on first use. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+	// Token is the only token the server accepts.
+	Token string
+
+	mu       sync.Mutex
+	guilds   map[string]*guild
+	requests []Request
+	faults   []fault
+	nextItem int
+
+	limit  int
+	window time.Duration
+	used   int
+	reset  time.Time
+}
+
+type guild struct {
+	info  v1.Guild
+	perms v1.Permissions
+	users map[string]*account
+	items map[string]v1.Item
+}
+
+type account struct {
+	cash, bank v1.Amount
+	inventory  map[string]int
+}
+
+type fault struct {
+	status     int
+	body       []byte
+	retryAfter time.Duration
+}
+
+// NewServer starts a fake API that accepts token.
+func NewServer(token string) *Server {
+	s := &Server{Token: token, guilds: make(map[string]*guild)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// NewClient returns a client authenticated with the server's token and
+// pointed at it. opts are applied after the base URL.
+func (s *Server) NewClient(opts ...v1.Option) (*v1.Client, error) {
+	return v1.NewClient(s.Token, append([]v1.Option{v1.WithBaseURL(s.URL)}, opts...)...)
+}
+
+// AddGuild sets the details GetGuild returns for g.ID.
+func (s *Server) AddGuild(g v1.Guild) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.guild(g.ID).info = g
+}
+
+// SetPermissions sets the permissions a guild has granted the application.
+func (s *Server) SetPermissions(guildID string, perms v1.Permissions) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.guild(guildID).perms = perms
+}
+
+// SetBalance sets a user's balance.
+func (s *Server) SetBalance(guildID, userID string, cash, bank v1.Amount) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a := s.guild(guildID).account(userID)
+	a.cash, a.bank = cash, bank
+}
+
+// Balance returns a user's balance, and whether the user exists.
+func (s *Server) Balance(guildID, userID string) (v1.Balance, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g := s.guilds[guildID]
+	if g == nil || g.users[userID] == nil {
+		return v1.Balance{}, false
+	}
+	return g.balance(userID), true
+}
+
+// AddItem adds item to a guild's store and returns it. An ID is assigned if
+// item has none.
+func (s *Server) AddItem(guildID string, item v1.Item) v1.Item {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if item.ID == "" {
+		s.nextItem++
+		item.ID = strconv.Itoa(s.nextItem)
+	}
+	s.guild(guildID).items[item.ID] = item
+	return item
+}
+
+// SetInventory sets how many of an item a user has.
+func (s *Server) SetInventory(guildID, userID, itemID string, quantity int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inv := s.guild(guildID).account(userID).inventory
+	if quantity <= 0 {
+		delete(inv, itemID)
+		return
+	}
+	inv[itemID] = quantity
+}
+
+// Inventory returns how many of an item a user has.
+func (s *Server) Inventory(guildID, userID, itemID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g := s.guilds[guildID]
+	if g == nil || g.users[userID] == nil {
+		return 0
+	}
+	return g.users[userID].inventory[itemID]
+}
+
+// FailNext makes the next request that passes authentication fail with
+// status and message, e.g. FailNext(503, "").
+func (s *Server) FailNext(status int, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, fault{status: status, body: errorBody(status, message)})
+}
+
+// RateLimitNext makes the next request that passes authentication fail with
+// 429 Too Many Requests and the given retry-after.
+func (s *Server) RateLimitNext(retryAfter time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, fault{status: http.StatusTooManyRequests, retryAfter: retryAfter})
+}
+
+// SetRateLimit allows limit requests per window, reporting the quota in
+// X-RateLimit headers and answering 429 once it is used up. A limit of 0
+// turns rate limiting off, which is the default.
+func (s *Server) SetRateLimit(limit int, window time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limit, s.window, s.used, s.reset = limit, window, 0, time.Time{}
+}
+
+// Requests returns every request received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// guild returns the guild with id, creating it if needed. s.mu must be held.
+func (s *Server) guild(id string) *guild {
+	g := s.guilds[id]
+	if g == nil {
+		g = &guild{
+			info:  v1.Guild{ID: id, Name: "Guild " + id, Symbol: "$"},
+			perms: AllPermissions,
+			users: make(map[string]*account),
+			items: make(map[string]v1.Item),
+		}
+		s.guilds[id] = g
+	}
+	return g
+}
+
+func (g *guild) account(id string) *account {
+	a := g.users[id]
+	if a == nil {
+		a = &account{inventory: make(map[string]int)}
+		g.users[id] = a
+	}
+	return a
+}
+
+func (g *guild) balance(id string) v1.Balance {
+	a := g.users[id]
+	return v1.Balance{UserID: id, Cash: a.cash, Bank: a.bank, Total: a.cash.Add(a.bank)}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.RequestURI(), Body: body})
+
+	w.Header().Set("Content-Type", "application/json")
+	if r.Header.Get("Authorization") != s.Token {
+		writeError(w, http.StatusUnauthorized, "")
+		return
+	}
+	if len(s.faults) > 0 {
+		f := s.faults[0]
+		s.faults = s.faults[1:]
+		if f.status == http.StatusTooManyRequests {
+			writeRateLimited(w, f.retryAfter)
+			return
+		}
+		w.WriteHeader(f.status)
+		w.Write(f.body)
+		return
+	}
+	if s.limit > 0 {
+		now := time.Now()
+		if !now.Before(s.reset) {
+			// Whole milliseconds, so clients waiting for the reset header
+			// don't come back early.
+			s.used, s.reset = 0, now.Add(s.window).Truncate(time.Millisecond).Add(time.Millisecond)
+		}
+		h := w.Header()
+		h.Set("X-RateLimit-Limit", strconv.Itoa(s.limit))
+		h.Set("X-RateLimit-Reset", strconv.FormatInt(s.reset.UnixNano()/int64(time.Millisecond), 10))
+		h.Set("X-RateLimit-Bucket", "unbtest")
+		if s.used >= s.limit {
+			h.Set("X-RateLimit-Remaining", "0")
+			writeRateLimited(w, s.reset.Sub(now))
+			return
+		}
+		s.used++
+		h.Set("X-RateLimit-Remaining", strconv.Itoa(s.limit-s.used))
+	}
+	s.route(w, r, body)
+}
+
+func (s *Server) route(w http.ResponseWriter, r *http.Request, body []byte) {
+	var parts []string
+	for _, p := range strings.Split(strings.Trim(r.URL.EscapedPath(), "/"), "/") {
+		if p, err := url.PathUnescape(p); err == nil && p != "" {
+			parts = append(parts, p)
+		}
+	}
+	q := r.URL.Query()
+	switch {
+	case len(parts) == 4 && parts[0] == "applications" && parts[1] == "@me" && parts[2] == "guilds":
+		if g := s.guild(parts[3]); allow(w, r, "GET") {
+			writeJSON(w, map[string]v1.Permissions{"permissions": g.perms})
+		}
+	case len(parts) < 2 || parts[0] != "guilds":
+		writeError(w, http.StatusNotFound, "")
+	case len(parts) == 2:
+		if g := s.guild(parts[1]); allow(w, r, "GET") {
+			writeJSON(w, g.info)
+		}
+	case len(parts) == 3 && parts[2] == "users":
+		if g := s.guild(parts[1]); allow(w, r, "GET") && g.can(w, v1.PermissionEconomyRead) {
+			g.leaderboard(w, q)
+		}
+	case len(parts) == 4 && parts[2] == "users":
+		if g := s.guild(parts[1]); allow(w, r, "GET", "PUT", "PATCH") {
+			g.userBalance(w, r.Method, parts[3], body)
+		}
+	case len(parts) == 5 && parts[2] == "users" && parts[4] == "inventory":
+		if g := s.guild(parts[1]); allow(w, r, "GET", "POST") {
+			g.inventory(w, r.Method, parts[3], q, body)
+		}
+	case len(parts) == 6 && parts[2] == "users" && parts[4] == "inventory":
+		if g := s.guild(parts[1]); allow(w, r, "GET", "DELETE") {
+			g.inventoryItem(w, r.Method, parts[3], parts[5], body)
+		}
+	case len(parts) == 3 && parts[2] == "items":
+		if g := s.guild(parts[1]); allow(w, r, "GET", "POST") {
+			s.items(w, g, r.Method, q, body)
+		}
+	case len(parts) == 4 && parts[2] == "items":
+		if g := s.guild(parts[1]); allow(w, r, "GET", "PATCH", "DELETE") {
+			g.item(w, r.Method, parts[3], body)
+		}
+	default:
+		writeError(w, http.StatusNotFound, "")
+	}
+}
+
+func allow(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, m := range methods {
+		if r.Method == m {
+			return true
+		}
+	}
+	writeError(w, http.StatusMethodNotAllowed, "")
+	return false
+}
+
+func (g *guild) can(w http.ResponseWriter, p v1.Permissions) bool {
+	if !g.perms.Has(p) {
+		writeError(w, http.StatusForbidden, "Missing Permissions")
+		return false
+	}
+	return true
+}
+
+func (g *guild) leaderboard(w http.ResponseWriter, q url.Values) {
+	sortBy := q.Get("sort")
+	key := func(b v1.Balance) v1.Amount {
+		switch sortBy {
+		case v1.SortByCash:
+			return b.Cash
+		case v1.SortByBank:
+			return b.Bank
+		}
+		return b.Total
+	}
+	switch sortBy {
+	case "", v1.SortByTotal, v1.SortByCash, v1.SortByBank:
+	default:
+		writeError(w, http.StatusBadRequest, "Invalid sort")
+		return
+	}
+	entries := make([]v1.LeaderboardEntry, 0, len(g.users))
+	for id := range g.users {
+		entries = append(entries, v1.LeaderboardEntry{Balance: g.balance(id)})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if c := key(entries[i].Balance).Cmp(key(entries[j].Balance)); c != 0 {
+			return c > 0
+		}
+		return entries[i].UserID < entries[j].UserID
+	})
+	for i := range entries {
+		entries[i].Rank = i + 1
+	}
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	entries = entries[min(max(offset, 0), len(entries)):]
+	page, limit, paged, ok := paging(w, q, 1000)
+	if !ok {
+		return
+	}
+	total := (len(entries) + limit - 1) / limit
+	entries = entries[min((page-1)*limit, len(entries)):min(page*limit, len(entries))]
+	if paged {
+		writeJSON(w, map[string]interface{}{"users": entries, "total_pages": total})
+		return
+	}
+	writeJSON(w, entries)
+}
+
+func (g *guild) userBalance(w http.ResponseWriter, method, userID string, body []byte) {
+	if method == "GET" {
+		if !g.can(w, v1.PermissionEconomyRead) {
+			return
+		}
+		if g.users[userID] == nil {
+			writeError(w, http.StatusNotFound, "Unknown user")
+			return
+		}
+		b := g.balance(userID)
+		for id := range g.users {
+			if g.balance(id).Total.Cmp(b.Total) > 0 {
+				b.Rank++
+			}
+		}
+		b.Rank++
+		writeJSON(w, b)
+		return
+	}
+	if !g.can(w, v1.PermissionEconomyWrite) {
+		return
+	}
+	var change struct {
+		Cash   *v1.Amount `json:"cash"`
+		Bank   *v1.Amount `json:"bank"`
+		Reason string     `json:"reason"`
+	}
+	if err := json.Unmarshal(body, &change); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if change.Cash == nil && change.Bank == nil {
+		writeError(w, http.StatusBadRequest, "Nothing to update")
+		return
+	}
+	a := g.account(userID)
+	if change.Cash != nil {
+		if method == "PATCH" {
+			a.cash = a.cash.Add(*change.Cash)
+		} else {
+			a.cash = *change.Cash
+		}
+	}
+	if change.Bank != nil {
+		if method == "PATCH" {
+			a.bank = a.bank.Add(*change.Bank)
+		} else {
+			a.bank = *change.Bank
+		}
+	}
+	writeJSON(w, g.balance(userID))
+}
+
+func (s *Server) items(w http.ResponseWriter, g *guild, method string, q url.Values, body []byte) {
+	if method == "GET" {
+		ids := make([]string, 0, len(g.items))
+		for id := range g.items {
+			ids = append(ids, id)
+		}
+		sortIDs(ids)
+		page, limit, _, ok := paging(w, q, len(ids)+1)
+		if !ok {
+			return
+		}
+		items := []v1.Item{}
+		for _, id := range ids[min((page-1)*limit, len(ids)):min(page*limit, len(ids))] {
+			items = append(items, g.items[id])
+		}
+		writeJSON(w, map[string]interface{}{"items": items, "total_pages": (len(ids) + limit - 1) / limit})
+		return
+	}
+	if !g.can(w, v1.PermissionItems) {
+		return
+	}
+	var item v1.Item
+	if err := json.Unmarshal(body, &item); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if item.Name == "" {
+		writeError(w, http.StatusBadRequest, "Missing name")
+		return
+	}
+	s.nextItem++
+	item.ID = strconv.Itoa(s.nextItem)
+	g.items[item.ID] = item
+	writeJSON(w, item)
+}
+
+func (g *guild) item(w http.ResponseWriter, method, itemID string, body []byte) {
+	item, ok := g.items[itemID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Unknown item")
+		return
+	}
+	switch method {
+	case "GET":
+		writeJSON(w, item)
+		return
+	case "DELETE":
+		if g.can(w, v1.PermissionItems) {
+			delete(g.items, itemID)
+			w.WriteHeader(http.StatusNoContent)
+		}
+		return
+	}
+	if !g.can(w, v1.PermissionItems) {
+		return
+	}
+	// Apply the patch on top of the item's current fields.
+	var fields map[string]json.RawMessage
+	current, _ := json.Marshal(item)
+	json.Unmarshal(current, &fields)
+	var patch map[string]json.RawMessage
+	if err := json.Unmarshal(body, &patch); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	for k, v := range patch {
+		fields[k] = v
+	}
+	merged, _ := json.Marshal(fields)
+	var updated v1.Item
+	if err := json.Unmarshal(merged, &updated); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	updated.ID = itemID
+	g.items[itemID] = updated
+	writeJSON(w, updated)
+}
+
+func (g *guild) stack(userID, itemID string) v1.InventoryItem {
+	item := g.items[itemID]
+	stack := v1.InventoryItem{ItemID: itemID, Name: item.Name, Emoji: item.Emoji}
+	if a := g.users[userID]; a != nil {
+		stack.Quantity = a.inventory[itemID]
+	}
+	return stack
+}
+
+func (g *guild) inventory(w http.ResponseWriter, method, userID string, q url.Values, body []byte) {
+	if method == "GET" {
+		var ids []string
+		if a := g.users[userID]; a != nil {
+			for id := range a.inventory {
+				ids = append(ids, id)
+			}
+		}
+		sortIDs(ids)
+		page, limit, _, ok := paging(w, q, len(ids)+1)
+		if !ok {
+			return
+		}
+		stacks := []v1.InventoryItem{}
+		for _, id := range ids[min((page-1)*limit, len(ids)):min(page*limit, len(ids))] {
+			stacks = append(stacks, g.stack(userID, id))
+		}
+		writeJSON(w, map[string]interface{}{"items": stacks, "total_pages": (len(ids) + limit - 1) / limit})
+		return
+	}
+	if !g.can(w, v1.PermissionInventory) {
+		return
+	}
+	var change struct {
+		ItemID   string `json:"item_id"`
+		Quantity int    `json:"quantity"`
+	}
+	if err := json.Unmarshal(body, &change); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, ok := g.items[change.ItemID]; !ok {
+		writeError(w, http.StatusNotFound, "Unknown item")
+		return
+	}
+	if change.Quantity < 1 {
+		writeError(w, http.StatusBadRequest, "Invalid quantity")
+		return
+	}
+	g.account(userID).inventory[change.ItemID] += change.Quantity
+	writeJSON(w, g.stack(userID, change.ItemID))
+}
+
+func (g *guild) inventoryItem(w http.ResponseWriter, method, userID, itemID string, body []byte) {
+	stack := g.stack(userID, itemID)
+	if stack.Quantity == 0 {
+		writeError(w, http.StatusNotFound, "Unknown item")
+		return
+	}
+	if method == "GET" {
+		writeJSON(w, stack)
+		return
+	}
+	if !g.can(w, v1.PermissionInventory) {
+		return
+	}
+	var change struct {
+		Quantity int `json:"quantity"`
+	}
+	if err := json.Unmarshal(body, &change); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	switch {
+	case change.Quantity < 1:
+		writeError(w, http.StatusBadRequest, "Invalid quantity")
+		return
+	case change.Quantity > stack.Quantity:
+		writeError(w, http.StatusBadRequest, "Not enough items")
+		return
+	}
+	inv := g.users[userID].inventory
+	if inv[itemID] -= change.Quantity; inv[itemID] == 0 {
+		delete(inv, itemID)
+	}
+	writeJSON(w, g.stack(userID, itemID))
+}
+
+// paging reads the page and limit query parameters. paged reports whether a
+// page was asked for.
+func paging(w http.ResponseWriter, q url.Values, defaultLimit int) (page, limit int, paged, ok bool) {
+	page, limit = 1, defaultLimit
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{{"page", &page}, {"limit", &limit}} {
+		if v := q.Get(p.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				writeError(w, http.StatusBadRequest, "Invalid "+p.name)
+				return 0, 0, false, false
+			}
+			*p.dst = n
+		}
+	}
+	return page, limit, q.Get("page") != "", true
+}
+
+// sortIDs sorts numeric IDs numerically and the rest after them.
+func sortIDs(ids []string) {
+	sort.Slice(ids, func(i, j int) bool {
+		a, errA := strconv.Atoi(ids[i])
+		b, errB := strconv.Atoi(ids[j])
+		if errA == nil && errB == nil {
+			return a < b
+		}
+		if (errA == nil) != (errB == nil) {
+			return errA == nil
+		}
+		return ids[i] < ids[j]
+	})
+}
+
+func errorBody(status int, message string) []byte {
+	resp := map[string]string{"error": fmt.Sprintf("%d: %s", status, http.StatusText(status))}
+	if message != "" {
+		resp["message"] = message
+	}
+	b, _ := json.Marshal(resp)
+	return b
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	w.Write(errorBody(status, message))
+}
+
+func writeRateLimited(w http.ResponseWriter, retryAfter time.Duration) {
+	w.WriteHeader(http.StatusTooManyRequests)
+	writeJSON(w, map[string]interface{}{
+		"message":     "You are being rate limited.",
+		"retry_after": int64((retryAfter + time.Millisecond - 1) / time.Millisecond),
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,226 @@
+package unbtest_test
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	v1 "github.com/BaileyJM02/unb-api-go/v1"
+	"github.com/BaileyJM02/unb-api-go/v1/unbtest"
+)
+
+const (
+	guildID = "411898639737421824"
+	userID  = "398197113495748626"
+)
+
+// ok fails the test if an err is not nil.
+func ok(tb testing.TB, err error) {
+	if err != nil {
+		_, file, line, _ := runtime.Caller(1)
+		fmt.Printf("\033[31m%s:%d: unexpected error: %s\033[39m\n\n", filepath.Base(file), line, err.Error())
+		tb.FailNow()
+	}
+}
+
+// equals fails the test if exp is not equal to act.
+func equals(tb testing.TB, exp, act interface{}) {
+	if !reflect.DeepEqual(exp, act) {
+		_, file, line, _ := runtime.Caller(1)
+		fmt.Printf("\033[31m%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\033[39m\n\n", filepath.Base(file), line, exp, act)
+		tb.FailNow()
+	}
+}
+
+func newClient(t *testing.T, opts ...v1.Option) (*unbtest.Server, *v1.Client) {
+	srv := unbtest.NewServer("token")
+	t.Cleanup(srv.Close)
+	api, err := srv.NewClient(opts...)
+	ok(t, err)
+	return srv, api
+}
+
+func apiError(t *testing.T, err error) *v1.APIError {
+	var apiErr *v1.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *v1.APIError, got %#v", err)
+	}
+	return apiErr
+}
+
+func TestBalancesAreSetUpdatedAndRead(t *testing.T) {
+	srv, api := newClient(t)
+
+	_, err := api.GetBalance(guildID, userID)
+	equals(t, "Unknown user", apiError(t, err).Message)
+
+	bal, err := api.SetBalance(guildID, userID, 100, 50, "Start")
+	ok(t, err)
+	equals(t, v1.NewAmount(150), bal.Total)
+
+	bal, err = api.UpdateBalance(guildID, userID, -25, 0, "Fine")
+	ok(t, err)
+	equals(t, v1.NewAmount(75), bal.Cash)
+	equals(t, v1.NewAmount(50), bal.Bank)
+
+	bal, err = api.GetBalance(guildID, userID)
+	ok(t, err)
+	equals(t, 1, bal.Rank)
+	stored, found := srv.Balance(guildID, userID)
+	equals(t, true, found)
+	equals(t, v1.NewAmount(125), stored.Total)
+}
+
+func TestLeaderboardIsSortedAndPaged(t *testing.T) {
+	srv, api := newClient(t)
+	srv.SetBalance(guildID, "1", v1.NewAmount(10), v1.NewAmount(300))
+	srv.SetBalance(guildID, "2", v1.NewAmount(200), v1.NewAmount(0))
+	srv.SetBalance(guildID, "3", v1.NewAmount(50), v1.NewAmount(50))
+
+	entries, err := api.Leaderboard(guildID)
+	ok(t, err)
+	equals(t, 3, len(entries))
+	equals(t, "1", entries[0].UserID)
+	equals(t, 3, entries[2].Rank)
+
+	page, err := api.GetLeaderboardPage(guildID, v1.LeaderboardOptions{Sort: v1.SortByCash, Limit: 2, Page: 2})
+	ok(t, err)
+	equals(t, 2, page.TotalPages)
+	equals(t, 1, len(page.Entries))
+	equals(t, "1", page.Entries[0].UserID)
+
+	it := api.IterateLeaderboard(guildID, v1.LeaderboardOptions{Limit: 1})
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Value().UserID)
+	}
+	ok(t, it.Err())
+	equals(t, []string{"1", "2", "3"}, ids)
+}
+
+func TestGuildAndPermissions(t *testing.T) {
+	srv, api := newClient(t)
+	srv.AddGuild(v1.Guild{ID: guildID, Name: "Pizza Palace", Symbol: "£"})
+	srv.SetPermissions(guildID, v1.PermissionEconomyRead)
+
+	guild, err := api.GetGuild(guildID)
+	ok(t, err)
+	equals(t, "Pizza Palace", guild.Name)
+
+	perms, err := api.GetPermissions(guildID)
+	ok(t, err)
+	equals(t, v1.PermissionEconomyRead, perms)
+
+	_, err = api.SetBalance(guildID, userID, 1, 1, nil)
+	equals(t, "Missing Permissions", apiError(t, err).Message)
+
+	guild, err = api.GetGuild("1")
+	ok(t, err)
+	equals(t, "Guild 1", guild.Name)
+}
+
+func TestItemsCanBeCreatedUpdatedAndDeleted(t *testing.T) {
+	srv, api := newClient(t)
+	srv.AddItem(guildID, v1.Item{Name: "Cheese", Price: v1.NewAmount(5)})
+
+	item, err := api.CreateItem(guildID, v1.Item{Name: "Pizza", Price: v1.NewAmount(250), Stock: v1.Ptr(10)})
+	ok(t, err)
+	equals(t, "2", item.ID)
+
+	item, err = api.UpdateItem(guildID, item.ID, v1.ItemUpdate{Price: v1.Ptr(v1.NewAmount(300)), UnlimitedStock: true})
+	ok(t, err)
+	equals(t, "Pizza", item.Name)
+	equals(t, v1.NewAmount(300), item.Price)
+	equals(t, (*int)(nil), item.Stock)
+
+	var names []string
+	it := api.IterateItems(guildID, v1.ListOptions{Limit: 1})
+	for it.Next() {
+		names = append(names, it.Value().Name)
+	}
+	ok(t, it.Err())
+	equals(t, []string{"Cheese", "Pizza"}, names)
+
+	ok(t, api.DeleteItem(guildID, item.ID))
+	_, err = api.GetItem(guildID, item.ID)
+	equals(t, "Unknown item", apiError(t, err).Message)
+}
+
+func TestInventoryTracksQuantities(t *testing.T) {
+	srv, api := newClient(t)
+	pizza := srv.AddItem(guildID, v1.Item{Name: "Pizza", Emoji: "🍕"})
+
+	_, err := api.AddInventoryItem(guildID, userID, "missing", 1)
+	equals(t, "Unknown item", apiError(t, err).Message)
+
+	stack, err := api.AddInventoryItem(guildID, userID, pizza.ID, 3)
+	ok(t, err)
+	equals(t, v1.InventoryItem{ItemID: pizza.ID, Name: "Pizza", Emoji: "🍕", Quantity: 3}, stack)
+
+	_, err = api.RemoveInventoryItem(guildID, userID, pizza.ID, 5)
+	equals(t, "Not enough items", apiError(t, err).Message)
+
+	stack, err = api.RemoveInventoryItem(guildID, userID, pizza.ID, 3)
+	ok(t, err)
+	equals(t, 0, stack.Quantity)
+	equals(t, 0, srv.Inventory(guildID, userID, pizza.ID))
+
+	page, err := api.ListInventory(guildID, userID, v1.ListOptions{})
+	ok(t, err)
+	equals(t, 0, len(page.Items))
+}
+
+func TestBadTokenIsUnauthorized(t *testing.T) {
+	srv := unbtest.NewServer("token")
+	defer srv.Close()
+	api, err := v1.NewClient("wrong", v1.WithBaseURL(srv.URL))
+	ok(t, err)
+
+	status, err := api.Check()
+	equals(t, true, status.Up)
+	equals(t, 401, apiError(t, err).StatusCode)
+}
+
+func TestFailNextIsRetriedForIdempotentRequests(t *testing.T) {
+	srv, api := newClient(t, v1.WithRetryPolicy(v1.RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond}))
+	srv.SetBalance(guildID, userID, v1.NewAmount(1), v1.NewAmount(0))
+
+	srv.FailNext(503, "")
+	_, err := api.GetBalance(guildID, userID)
+	ok(t, err)
+
+	srv.FailNext(503, "")
+	_, err = api.UpdateBalance(guildID, userID, 1, 0, nil)
+	equals(t, 503, apiError(t, err).StatusCode)
+	bal, _ := srv.Balance(guildID, userID)
+	equals(t, v1.NewAmount(1), bal.Cash)
+}
+
+func TestRateLimitNextIsWaitedOut(t *testing.T) {
+	srv, api := newClient(t, v1.WithRetryPolicy(v1.RetryPolicy{MaxRetries: 1}))
+
+	srv.RateLimitNext(10 * time.Millisecond)
+	_, err := api.UpdateBalance(guildID, userID, 5, 0, nil)
+	ok(t, err)
+	equals(t, 2, len(srv.Requests()))
+}
+
+func TestSetRateLimitIsRespectedByTheClient(t *testing.T) {
+	srv, api := newClient(t)
+	srv.SetRateLimit(1, 50*time.Millisecond)
+
+	// The second request waits for the window to reset instead of being
+	// rejected.
+	for i := 0; i < 2; i++ {
+		_, err := api.SetBalance(guildID, userID, i, 0, nil)
+		ok(t, err)
+	}
+	equals(t, 2, len(srv.Requests()))
+	states := api.RateLimits()
+	equals(t, 1, len(states))
+	equals(t, 1, states[0].Limit)
+}
@@ -1,318 +1,289 @@
 package v1
 
 import(
+	"context"
 	"io/ioutil"
 	"net/http"
 	"time"
 	"errors"
 	"fmt"
 	"encoding/json"
-	"strconv"
-	"strings"
 	"bytes"
+	"log/slog"
 )
 
-type userData struct {
-    token string
-    client *http.Client
+// Request sends a request to the API and returns the raw response body.
+// It is shorthand for RequestContext with context.Background().
+func (u *Client) Request(protocol, url string, payload []byte) ([]byte, error) {
+	return u.RequestContext(context.Background(), protocol, url, payload)
 }
 
-type errorResponse struct {
-    Error string `json:"error"`
-    Message string `json:"message"`
-}
-
-type timeoutResponse struct {
-    Message string `json:"message"`
-    RetryAfter time.Duration `json:"retry_after"`
-}
-
-type check struct {
-    Ping time.Duration
-    Up bool
-}
-
-type userObj struct {
-    Rank int `json:"rank"`
-    UserId string `json:"user_id"`
-    Cash int `json:"cash"`
-    CashInfinite bool `json:"infinite_cash"`
-    CashNinfinite bool `json:"n-infinite_cash"`
-    Bank int `json:"bank"`
-    BankInfinite bool `json:"infinite_bank"`
-    BankNinfinite bool `json:"n-infinite_bank"`
-    Total int `json:"total"`
-    Infinite bool `json:"infinite_total"`
-    Ninfinite bool `json:"n-infinite_total"`
+// RequestContext sends a request to the API bound to ctx, retrying it as
+// allowed by the client's RetryPolicy. If ctx is cancelled or its deadline
+// passes before the response has been read, ctx.Err() is returned as-is so
+// callers can test for context.Canceled or context.DeadlineExceeded with
+// errors.Is.
+func (u *Client) RequestContext(ctx context.Context, protocol, url string, payload []byte) ([]byte, error) {
+	op := &Operation{Name: "Request", Method: protocol, Path: url, Payload: payload}
+	res, err := u.invoke(ctx, op, func(ctx context.Context, op *Operation) (interface{}, error) {
+		return u.perform(ctx, op)
+	})
+	respo, _ := res.([]byte)
+	return respo, err
 }
 
-type userObjwReason struct {
-    UserId string `json:"user_id"`
-    Cash int `json:"cash"`
-    CashInfinite bool `json:"infinite_cash"`
-    CashNinfinite bool `json:"n-infinite_cash"`
-    Bank int `json:"bank"`
-    BankInfinite bool `json:"infinite_bank"`
-    BankNinfinite bool `json:"n-infinite_bank"`
-    Reason string `json:"reason"`
+// request is RequestContext for callers that know better than the method
+// whether the request is safe to repeat.
+func (u *Client) request(ctx context.Context, protocol, url string, payload []byte, idempotent bool) ([]byte, error) {
+	if u.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, u.timeout)
+		defer cancel()
+	}
+	for attempt := 0; ; attempt++ {
+		actx, span := u.startAttempt(ctx, attempt, protocol, url)
+		respo, err := u.send(actx, protocol, url, payload)
+		endAttempt(ctx, span, err)
+		retry, waitErr := u.backOff(ctx, attempt, idempotent, protocol, url, err)
+		if waitErr != nil {
+			return nil, waitErr
+		}
+		if !retry {
+			return respo, err
+		}
+	}
 }
 
-type userObjRaw struct {
-    Rank interface{} `json:"rank"`
-    UserId interface{} `json:"user_id"`
-    Cash interface{} `json:"cash"`
-    Bank interface{} `json:"bank"`
-    Total interface{} `json:"total"`
+// backOff waits before a request that failed with err on attempt (0 being
+// the first) is sent again, if the retry policy allows it. It reports
+// whether the request should be retried, or returns ctx.Err() if ctx ended
+// while waiting.
+func (u *Client) backOff(ctx context.Context, attempt int, idempotent bool, protocol, url string, err error) (bool, error) {
+	delay, retry := u.retry.next(attempt, idempotent, err)
+	if !retry {
+		return false, nil
+	}
+	// Don't sleep past the deadline only to fail anyway.
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return false, nil
+	}
+	if u.logger != nil {
+		u.logger.LogAttrs(ctx, u.logLevels.Retry, "unb: retrying request",
+			slog.String("method", protocol), slog.String("path", url),
+			slog.Int("attempt", attempt+1), slog.Duration("delay", delay),
+			slog.String("error", u.redact(err.Error())))
+	}
+	var rateErr *RateLimitError
+	if op := operationFrom(ctx); op != nil && errors.As(err, &rateErr) {
+		op.RetryAfterWait += delay
+	}
+	if err := sleepContext(ctx, delay); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
-type userObjPut struct {
-    Cash interface{} `json:"cash,omitempty"`
-    Bank interface{} `json:"bank,omitempty"`
-    Reason interface{} `json:"reason,omitempty"`
+// RateLimits returns the state of every rate limit bucket the client has
+// seen so far. Requests are held back before sending while their bucket has
+// no requests remaining.
+func (u *Client) RateLimits() []BucketState {
+	return u.limiter.snapshot()
 }
 
-func (u *userData) Request(protocol, url string, payload []byte) ([]byte, error) {
+// send makes a single attempt at a request, once its rate limit bucket
+// allows it.
+func (u *Client) send(ctx context.Context, protocol, url string, payload []byte) ([]byte, error) {
+	op := operationFrom(ctx)
+	route := routeKey(protocol, url)
+	waited, err := u.limiter.wait(ctx, route)
+	if op != nil {
+		op.RateLimitWait += waited
+	}
+	if err != nil {
+		return nil, err
+	}
+	if waited > 0 && u.logger != nil {
+		u.logger.LogAttrs(ctx, u.logLevels.Retry, "unb: waited for rate limit",
+			slog.String("method", protocol), slog.String("path", url),
+			slog.Duration("waited", waited))
+	}
     b := bytes.NewBuffer(payload)
-	req, err := http.NewRequest(protocol, "https://unbelievable.pizza/api/v1"+url, b)
+	req, err := http.NewRequestWithContext(ctx, protocol, u.baseURL+url, b)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Add("Authorization", u.token)
+	if op != nil {
+		for k, v := range op.Header {
+			req.Header[k] = append(req.Header[k], v...)
+		}
+		op.Attempts++
+		op.StatusCode, op.Bucket = 0, ""
+	}
+	req.Header.Set("Authorization", u.token)
+	req.Header.Set("User-Agent", u.userAgent)
 	resp, err := u.client.Do(req)
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		return nil, err
 	}
 	defer resp.Body.Close()
+	if op != nil {
+		op.StatusCode, op.Bucket = resp.StatusCode, resp.Header.Get("X-RateLimit-Bucket")
+		if resp.StatusCode == http.StatusTooManyRequests {
+			op.RateLimited++
+		}
+	}
 	respo, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		return nil, err
 	}
-	if resp.StatusCode == 429 {
-	    err := timeoutResponse{}
-	    srsly := json.Unmarshal(respo, &err)
-	    if srsly != nil {
-	        // This is a srsly bad error -_-
-	        panic(err)
-	    }
-	    return respo, errors.New(fmt.Sprintf("%v Retry after: %s", err.Message, err.RetryAfter))
-	}
-	// Bit hacky, test if the response contains the error body
-	if strings.Contains(string(respo), "error") {
-	    err := errorResponse{}
-	    srsly := json.Unmarshal(respo, &err)
-	    if srsly != nil {
-	        // This is a srsly bad error -_-
-	        panic(err)
-	    }
-	    return respo, errors.New(fmt.Sprintf("%v (%v)", err.Error, err.Message))
+	err = parseError(resp, respo)
+	u.limiter.update(route, resp.Header, err)
+	if err != nil {
+		return respo, err
 	}
-	return respo, err
+	return respo, nil
 }
 
-func fixTypesToStruct(data []byte) (userObj, error) {
-    balUser := userObj{}
-    var objmap map[string]interface{}
-    err := json.Unmarshal(data, &objmap)
-    if err != nil {
-        return userObj{}, err
-    }
-    _, totalIsString := objmap["total"].(string)
-    if totalIsString {
-        switch x := objmap["total"]; x {
-            case "Infinity":
-                objmap["total"] = 0
-                objmap["infinite_total"] = true
-            case "-Infinity":
-		        objmap["total"] = -0
-                objmap["n-infinite_total"] = true
-	        default:
-	            objmap["total"], _ = strconv.ParseInt(objmap["total"].(string), 0, 64)
-	    }
-        
-    }
-    _, cashIsString := objmap["cash"].(string)
-    if cashIsString {
-        switch x := objmap["cash"]; x {
-            case "Infinity":
-                objmap["cash"] = 0
-                objmap["infinite_cash"] = true
-            case "-Infinity":
-		        objmap["cash"] = -0
-                objmap["n-infinite_cash"] = true
-	        default:
-		        objmap["cash"], _ = strconv.ParseInt(objmap["cash"].(string), 0, 64)
-	    }
-    }
-    _, bankIsString := objmap["bank"].(string)
-    if bankIsString {
-        switch x := objmap["bank"]; x {
-            case "Infinity":
-                objmap["bank"] = 0
-                objmap["infinite_bank"] = true
-            case "-Infinity":
-		        objmap["bank"] = -0
-                objmap["n-infinite_bank"] = true
-	        default:
-		        objmap["bank"], _ = strconv.ParseInt(objmap["bank"].(string), 0, 64)
-	    }
-    }
-    _, rankIsString := objmap["rank"].(string)
-    if rankIsString {
-        objmap["rank"], _ = strconv.ParseInt(objmap["rank"].(string), 0, 64)
-    }
-    
-    b, err := json.Marshal(objmap)
-    if err != nil {
-        panic(err)
-    }
-    
-    err = json.Unmarshal([]byte(b), &balUser)
-    if err != nil {
-        return userObj{}, err
-    }
-    
-    return balUser, err
+func decodeBalance(data []byte) (Balance, error) {
+	var bal Balance
+	if err := json.Unmarshal(data, &bal); err != nil {
+		return Balance{}, err
+	}
+	return bal, nil
 }
 
-func New(token string) userData {
-    client := &http.Client{}
-    u := userData{token, client}
+// New returns a client authenticated with token. It is shorthand for
+// NewClient with no options.
+func New(token string) *Client {
+    u, _ := NewClient(token)
     return u
 }
 
-func Custom(token string, client *http.Client) userData {
-    u := userData{token, client}
+// Custom returns a client authenticated with token that sends requests with
+// client. It is shorthand for NewClient with WithHTTPClient, except that a
+// nil client means the default one.
+func Custom(token string, client *http.Client) *Client {
+    u, err := NewClient(token, WithHTTPClient(client))
+    if err != nil {
+        return New(token)
+    }
     return u
 }
 
+// Check reports whether the API is reachable and the token is accepted.
+func (u *Client) Check() (HealthStatus, error) {
+	return u.CheckContext(context.Background())
+}
 
-func (u *userData) Check() (check, error) {
+// CheckContext is like Check but bound to ctx.
+func (u *Client) CheckContext(ctx context.Context) (HealthStatus, error) {
     start := time.Now()
-    data, err := u.Request("GET", "", nil)
+    _, err := call[[]byte](ctx, u, &Operation{Name: "Check", Method: "GET"}, nil)
     elapsed := time.Since(start)
-    if err != nil {
-        // because we never know how long.
-        if strings.Contains(string(data), `{"message":"You are being rate limited.","retry_after"`) {
-            return check{time.Since(time.Now()), true}, err
-        }
-        switch x := string(data); x {
-            case `{"error":"404: Not found"}`:
-                return check{elapsed, true}, nil
-                
-            case `{"error":"401: Unauthorized"}`:
-		        return check{elapsed, true}, errors.New("401 Unauthorized (Check your token)")
-		        
-	        default:
-		        return check{time.Since(time.Now()), false}, err
-	    }
-    }	
-	return check{time.Since(time.Now()), false}, errors.New("Cannot Connect to API url.")
+    var apiErr *APIError
+    var rateErr *RateLimitError
+    switch {
+        // Rate limited means up, even if we can't tell how fast.
+        case errors.As(err, &rateErr):
+            return HealthStatus{time.Since(time.Now()), true}, err
+        case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+            return HealthStatus{elapsed, true}, nil
+        case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized:
+            return HealthStatus{elapsed, true}, fmt.Errorf("%w (check your token)", err)
+        case err != nil:
+            return HealthStatus{time.Since(time.Now()), false}, err
+    }
+	return HealthStatus{time.Since(time.Now()), false}, errors.New("Cannot Connect to API url.")
 }
 
-func (u *userData) GetBalance(guild, user string) (userObj, error) {
-    data, err := u.Request("GET", fmt.Sprintf("/guilds/%v/users/%v", guild, user), nil)
-    if err != nil {
-        return userObj{}, err
-    }
-    userBal, err := fixTypesToStruct(data)
-    if err != nil {
-        return userObj{}, err
-    }
-	return userBal, err
+// GetBalance fetches a user's balance in a guild.
+func (u *Client) GetBalance(guild, user string) (Balance, error) {
+	return u.GetBalanceContext(context.Background(), guild, user)
 }
 
-func (u *userData) SetBalance(guild, user string, cash, bank, reason interface{}) (userObj, error) {
-    var payloadTypes = make(map[string]interface{})
-    switch x := cash; x.(type) {
-        case string:
-            if cash == "Infinity" {
-                payloadTypes["Cash"] = "Infinity"
-            } else if cash == "-Infinity" {
-                payloadTypes["Cash"] = "-Infinity"
-            }
-        case int:
-            payloadTypes["Cash"] = cash
-    }
-    switch x := bank; x.(type) {
-        case string:
-            if bank == "Infinity" {
-                payloadTypes["Bank"] = "Infinity"
-            } else if bank == "-Infinity" {
-                payloadTypes["Bank"] = "-Infinity"
-            }
-        case int:
-            payloadTypes["Bank"] = bank
-    }
-    switch x := reason; x.(type) {
-        case string:
-            payloadTypes["Reason"] = reason
-        case nil:
-            payloadTypes["Reason"] = "No reason provided."
+// GetBalanceContext is like GetBalance but bound to ctx.
+func (u *Client) GetBalanceContext(ctx context.Context, guild, user string) (Balance, error) {
+    op := &Operation{Name: "GetBalance", Method: "GET", Path: fmt.Sprintf("/guilds/%v/users/%v", guild, user), GuildID: guild, UserID: user}
+    return call(ctx, u, op, decodeBalance)
+}
+
+// SetBalance overwrites a user's cash and/or bank balance in a guild. cash
+// and bank may be any integer type, an Amount, a *big.Int, or a string such
+// as "Infinity" or "-Infinity"; nil leaves that field alone. reason may be a
+// string or nil. Unsupported values are reported as errors rather than being
+// dropped. New code should prefer ModifyBalance.
+func (u *Client) SetBalance(guild, user string, cash, bank, reason interface{}) (Balance, error) {
+	return u.SetBalanceContext(context.Background(), guild, user, cash, bank, reason)
+}
+
+// SetBalanceContext is like SetBalance but bound to ctx.
+func (u *Client) SetBalanceContext(ctx context.Context, guild, user string, cash, bank, reason interface{}) (Balance, error) {
+    update := NewBalanceUpdate()
+    if a, ok, err := amountOf(cash); err != nil {
+        return Balance{}, err
+    } else if ok {
+        update.SetCash(a)
     }
-    value, err := json.Marshal(payloadTypes)
-    if err != nil {
-        return userObj{}, err
+    if a, ok, err := amountOf(bank); err != nil {
+        return Balance{}, err
+    } else if ok {
+        update.SetBank(a)
     }
-    data, err := u.Request("PUT", fmt.Sprintf("/guilds/%v/users/%v", guild, user), value)
-    if err != nil {
-        return userObj{}, err
+    if err := legacyReason(update, reason); err != nil {
+        return Balance{}, err
     }
-    userBal, err := fixTypesToStruct(data)
-    if err != nil {
-        return userObj{}, err
+    return u.modifyBalance(ctx, "SetBalance", guild, user, update)
+}
+
+// UpdateBalance adds cash and bank (which may be negative) to a user's balance
+// in a guild. reason may be a string or nil. New code should prefer
+// ModifyBalance.
+func (u *Client) UpdateBalance(guild, user string, cash, bank int, reason interface{}) (Balance, error) {
+	return u.UpdateBalanceContext(context.Background(), guild, user, cash, bank, reason)
+}
+
+// UpdateBalanceContext is like UpdateBalance but bound to ctx.
+func (u *Client) UpdateBalanceContext(ctx context.Context, guild, user string, cash, bank int, reason interface{}) (Balance, error) {
+    update := NewBalanceUpdate().AddCash(NewAmount(int64(cash))).AddBank(NewAmount(int64(bank)))
+    if err := legacyReason(update, reason); err != nil {
+        return Balance{}, err
     }
-	return userBal, err
+    return u.modifyBalance(ctx, "UpdateBalance", guild, user, update)
 }
 
-func (u *userData) UpdateBalance(guild, user string, cash, bank int, reason interface{}) (userObj, error) {
-    var payloadTypes = make(map[string]interface{})
-    payloadTypes["Cash"] = cash
-    payloadTypes["Bank"] = bank
-    switch x := reason; x.(type) {
+func legacyReason(update *BalanceUpdate, reason interface{}) error {
+    switch r := reason.(type) {
         case string:
-            payloadTypes["Reason"] = reason
+            update.Reason(r)
         case nil:
-            payloadTypes["Reason"] = "No reason provided."
-    }
-    value, err := json.Marshal(payloadTypes)
-    if err != nil {
-        return userObj{}, err
+            update.Reason("No reason provided.")
+        default:
+            return fmt.Errorf("%w: unsupported reason type %T", ErrInvalidUpdate, reason)
     }
-    data, err := u.Request("PATCH", fmt.Sprintf("/guilds/%v/users/%v", guild, user), value)
-    if err != nil {
-        return userObj{}, err
-    }
-    userBal, err := fixTypesToStruct(data)
-    if err != nil {
-        return userObj{}, err
-    }
-	return userBal, err
+    return nil
 }
 
-func (u *userData) Leaderboard(guild string) ([]userObj, error) {
-    var leaderboardRaw []userObjRaw
-    var leaderboard []userObj
-    
-    data, err := u.Request("GET", fmt.Sprintf("/guilds/%v/users", guild), nil)
-    if err != nil {
-        return []userObj{}, err
-    }
-    
-    if err := json.Unmarshal(data, &leaderboardRaw)
-    err != nil {
-        return []userObj{}, err
-    }
-    for _, v := range leaderboardRaw {
-        value := fmt.Sprintf(`{"rank":"%v","user_id":"%v","cash":"%v","bank":"%v","total":"%v"}`,v.Rank,v.UserId,v.Cash,v.Bank,v.Total)
-        user, err := fixTypesToStruct([]byte(value))
-        if err != nil {
-            return []userObj{}, err
-        }
-        leaderboard = append(leaderboard, user)
-    }
+// Leaderboard fetches the balance leaderboard of a guild.
+func (u *Client) Leaderboard(guild string) ([]LeaderboardEntry, error) {
+	return u.LeaderboardContext(context.Background(), guild)
+}
 
+// LeaderboardContext is like Leaderboard but bound to ctx.
+func (u *Client) LeaderboardContext(ctx context.Context, guild string) ([]LeaderboardEntry, error) {
+    op := &Operation{Name: "Leaderboard", Method: "GET", Path: fmt.Sprintf("/guilds/%v/users", guild), GuildID: guild}
+    leaderboard, err := call(ctx, u, op, func(data []byte) ([]LeaderboardEntry, error) {
+        var leaderboard []LeaderboardEntry
+        err := json.Unmarshal(data, &leaderboard)
+        return leaderboard, err
+    })
     if err != nil {
-        return []userObj{}, err
+        return []LeaderboardEntry{}, err
     }
-	return leaderboard, err
+	return leaderboard, nil
 }
\ No newline at end of file
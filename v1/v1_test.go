@@ -2,6 +2,7 @@ package v1
 
 import (
 	"bytes"
+	"context"
 	"io/ioutil"
 	"net/http"
 	"testing"
@@ -10,7 +11,7 @@ import (
 	"runtime"
 	"reflect"
 	"errors"
-
+	"time"
 )
 
 
@@ -118,7 +119,10 @@ func TestCheckReturnsIsUpButErrorCannotConnectOn401(t *testing.T) {
 	api := Custom("token", client)
 	check, err := api.Check()
 	equals(t, true, check.Up)
-	equals(t, errors.New("401 Unauthorized (Check your token)"), err)
+	var apiErr *APIError
+	assert(t, errors.As(err, &apiErr), "expected *APIError, got %#v", err)
+	equals(t, 401, apiErr.StatusCode)
+	equals(t, "401: Unauthorized (check your token)", err.Error())
 
 }
 
@@ -127,7 +131,8 @@ func TestCheckReturnsIsDownWhen500(t *testing.T) {
 
 	api := Custom("token", client)
 	check, err := api.Check()
-	equals(t, errors.New("Cannot Connect to API url."), err)
+	equals(t, &APIError{StatusCode: 500, Body: []byte{}}, err)
+	equals(t, "500: Internal Server Error", err.Error())
 	equals(t, false, check.Up)
 }
 
@@ -136,7 +141,8 @@ func TestClientReturnsRatelimitedWhen427(t *testing.T) {
 
 	api := Custom("token", client)
 	check, err := api.Check()
-	equals(t, errors.New("You are being rate limited. Retry after: 36.191µs"), err)
+	equals(t, &RateLimitError{"You are being rate limited.", 36191 * time.Millisecond, []byte(`{"message":"You are being rate limited.","retry_after":36191}`)}, err)
+	equals(t, "You are being rate limited. Retry after: 36.191s", err.Error())
 	equals(t, true, check.Up)
 }
 
@@ -146,7 +152,7 @@ func TestGetBalanceHandlesDataOnSuccessfulFetchCorrectlyNonInfinite(t *testing.T
 	api := Custom("token", client)
 	data, err := api.GetBalance("411898639737421824", "398197113495748626") // Guild, User
 	ok(t, err)
-	equals(t, userObj{14,"398197113495748626",25,false,false,200,false,false,526,false,false }, data)
+	equals(t, Balance{14, "398197113495748626", NewAmount(25), NewAmount(200), NewAmount(526)}, data)
 }
 
 func TestGetBalanceHandlesDataOnSuccessfulFetchCorrectlyWithNoRank(t *testing.T) {
@@ -155,7 +161,7 @@ func TestGetBalanceHandlesDataOnSuccessfulFetchCorrectlyWithNoRank(t *testing.T)
 	api := Custom("token", client)
 	data, err := api.GetBalance("411898639737421824", "398197113495748626") // Guild, User
 	ok(t, err)
-	equals(t, userObj{0,"398197113495748626",25,false,false,200,false,false,225,false,false }, data)
+	equals(t, Balance{0, "398197113495748626", NewAmount(25), NewAmount(200), NewAmount(225)}, data)
 }
 
 func TestGetBalanceHandlesDataOnSuccessfulFetchCorrectlyWithNoRankWithInfiniteCash(t *testing.T) {
@@ -164,7 +170,7 @@ func TestGetBalanceHandlesDataOnSuccessfulFetchCorrectlyWithNoRankWithInfiniteCa
 	api := Custom("token", client)
 	data, err := api.GetBalance("411898639737421824", "398197113495748626") // Guild, User
 	ok(t, err)
-	equals(t, userObj{0,"398197113495748626",0,true,false,200,false,false,0,true,false }, data)
+	equals(t, Balance{0, "398197113495748626", Inf(1), NewAmount(200), Inf(1)}, data)
 }
 
 func TestGetBalanceHandlesDataOnSuccessfulFetchCorrectlyWithNoRankWithInfiniteBank(t *testing.T) {
@@ -173,7 +179,7 @@ func TestGetBalanceHandlesDataOnSuccessfulFetchCorrectlyWithNoRankWithInfiniteBa
 	api := Custom("token", client)
 	data, err := api.GetBalance("411898639737421824", "398197113495748626") // Guild, User
 	ok(t, err)
-	equals(t, userObj{0,"398197113495748626",25,false,false,0,true,false,0,true,false }, data)
+	equals(t, Balance{0, "398197113495748626", NewAmount(25), Inf(1), Inf(1)}, data)
 }
 
 func TestGetBalanceHandlesDataOnSuccessfulFetchCorrectlyWithNoRankWithInfiniteBankAndCash(t *testing.T) {
@@ -182,7 +188,7 @@ func TestGetBalanceHandlesDataOnSuccessfulFetchCorrectlyWithNoRankWithInfiniteBa
 	api := Custom("token", client)
 	data, err := api.GetBalance("411898639737421824", "398197113495748626") // Guild, User
 	ok(t, err)
-	equals(t, userObj{0,"398197113495748626",0,true,false,0,true,false,0,true,false }, data)
+	equals(t, Balance{0, "398197113495748626", Inf(1), Inf(1), Inf(1)}, data)
 }
 
 func TestGetBalanceHandlesDataOnSuccessfulFetchCorrectlyWithNoRankWithNegitiveInfiniteCash(t *testing.T) {
@@ -191,7 +197,7 @@ func TestGetBalanceHandlesDataOnSuccessfulFetchCorrectlyWithNoRankWithNegitiveIn
 	api := Custom("token", client)
 	data, err := api.GetBalance("411898639737421824", "398197113495748626") // Guild, User
 	ok(t, err)
-	equals(t, userObj{0,"398197113495748626",0,false,true,200,false,false,0,false,true }, data)
+	equals(t, Balance{0, "398197113495748626", Inf(-1), NewAmount(200), Inf(-1)}, data)
 }
 
 func TestGetBalanceHandlesDataOnSuccessfulFetchCorrectlyWithNoRankWithNegitiveInfiniteBank(t *testing.T) {
@@ -200,7 +206,7 @@ func TestGetBalanceHandlesDataOnSuccessfulFetchCorrectlyWithNoRankWithNegitiveIn
 	api := Custom("token", client)
 	data, err := api.GetBalance("411898639737421824", "398197113495748626") // Guild, User
 	ok(t, err)
-	equals(t, userObj{0,"398197113495748626",25,false,false,0,false,true,0,false,true }, data)
+	equals(t, Balance{0, "398197113495748626", NewAmount(25), Inf(-1), Inf(-1)}, data)
 }
 
 func TestGetBalanceHandlesDataOnSuccessfulFetchCorrectlyWithNoRankWithNegitiveInfiniteBankAndCash(t *testing.T) {
@@ -209,7 +215,7 @@ func TestGetBalanceHandlesDataOnSuccessfulFetchCorrectlyWithNoRankWithNegitiveIn
 	api := Custom("token", client)
 	data, err := api.GetBalance("411898639737421824", "398197113495748626") // Guild, User
 	ok(t, err)
-	equals(t, userObj{0,"398197113495748626",0,false,true,0,false,true,0,false,true }, data)
+	equals(t, Balance{0, "398197113495748626", Inf(-1), Inf(-1), Inf(-1)}, data)
 }
 
 func TestGetBalanceHandlesDataOnSuccessfulFetchCorrectlyWithNoRankWithNegitiveInfiniteBankAndInfiniteCash(t *testing.T) {
@@ -218,7 +224,7 @@ func TestGetBalanceHandlesDataOnSuccessfulFetchCorrectlyWithNoRankWithNegitiveIn
 	api := Custom("token", client)
 	data, err := api.GetBalance("411898639737421824", "398197113495748626") // Guild, User
 	ok(t, err)
-	equals(t, userObj{0,"398197113495748626",0,true,false,0,false,true,0,false,false }, data)
+	equals(t, Balance{0, "398197113495748626", Inf(1), Inf(-1), NewAmount(0)}, data)
 }
 
 func TestGetBalanceHandlesDataOnSuccessfulFetchCorrectlyWithNoRankWithInfiniteBankAndNegitiveInfiniteCash(t *testing.T) {
@@ -227,7 +233,7 @@ func TestGetBalanceHandlesDataOnSuccessfulFetchCorrectlyWithNoRankWithInfiniteBa
 	api := Custom("token", client)
 	data, err := api.GetBalance("411898639737421824", "398197113495748626") // Guild, User
 	ok(t, err)
-	equals(t, userObj{0,"398197113495748626",0,false,true,0,true,false,0,false,false }, data)
+	equals(t, Balance{0, "398197113495748626", Inf(-1), Inf(1), NewAmount(0)}, data)
 }
 
 func TestGetBalanceHandlesDataOnUnsuccessfulFetchCorrectlyWithIncorrectGuild(t *testing.T) {
@@ -235,8 +241,9 @@ func TestGetBalanceHandlesDataOnUnsuccessfulFetchCorrectlyWithIncorrectGuild(t *
 
 	api := Custom("token", client)
 	data, err := api.GetBalance("000000000000000000", "398197113495748626") // Guild, User
-	equals(t, userObj{}, data)
-	equals(t, errors.New("404: Not found (Unknown guild)"), err)
+	equals(t, Balance{}, data)
+	equals(t, &APIError{404, "404: Not found", "Unknown guild", []byte(`{"error":"404: Not found","message":"Unknown guild"}`)}, err)
+	equals(t, "404: Not found (Unknown guild)", err.Error())
 }
 
 func TestGetBalanceHandlesDataOnUnsuccessfulFetchCorrectlyWithIncorrectUser(t *testing.T) {
@@ -244,8 +251,9 @@ func TestGetBalanceHandlesDataOnUnsuccessfulFetchCorrectlyWithIncorrectUser(t *t
 
 	api := Custom("token", client)
 	data, err := api.GetBalance("411898639737421824", "000000000000000000") // Guild, User
-	equals(t, userObj{}, data)
-	equals(t, errors.New("404: Not found (Unknown user)"), err)
+	equals(t, Balance{}, data)
+	equals(t, &APIError{404, "404: Not found", "Unknown user", []byte(`{"error":"404: Not found","message":"Unknown user"}`)}, err)
+	equals(t, "404: Not found (Unknown user)", err.Error())
 }
 
 func TestLeaderboardHandlesDataOnSuccessfulFetchCorrectly(t *testing.T) {
@@ -254,8 +262,8 @@ func TestLeaderboardHandlesDataOnSuccessfulFetchCorrectly(t *testing.T) {
 	api := Custom("token", client)
 	data, err := api.Leaderboard("411898639737421824") // Guild
 	ok(t, err)
-	equals(t, []userObj{userObj{1, "116293018742554625", 0, true, false, 0, false, false, 0, true, false}, userObj{2, "398197113495748626", 0, false, true, 0, true, false, 0, false, false}, userObj{3, "000000000000000000", 33, false, false, 0, true, false, 0, true, false}}, data)
-	equals(t,userObj{1,"116293018742554625",0,true,false,0,false,false,0,true,false } ,data[0])
+	equals(t, []LeaderboardEntry{{Balance{1, "116293018742554625", Inf(1), NewAmount(0), Inf(1)}}, {Balance{2, "398197113495748626", Inf(-1), Inf(1), NewAmount(0)}}, {Balance{3, "000000000000000000", NewAmount(33), Inf(1), Inf(1)}}}, data)
+	equals(t, LeaderboardEntry{Balance{1, "116293018742554625", Inf(1), NewAmount(0), Inf(1)}}, data[0])
 }
 
 func TestLeaderboardHandlesErrorOnUnsuccessfulFetchCorrectly(t *testing.T) {
@@ -263,8 +271,9 @@ func TestLeaderboardHandlesErrorOnUnsuccessfulFetchCorrectly(t *testing.T) {
 
 	api := Custom("token", client)
 	data, err := api.Leaderboard("000000000000000000") // Guild
-	equals(t, []userObj{}, data)
-	equals(t, errors.New("404: Not found (Unknown guild)"), err)
+	equals(t, []LeaderboardEntry{}, data)
+	equals(t, &APIError{404, "404: Not found", "Unknown guild", []byte(`{"error":"404: Not found","message":"Unknown guild"}`)}, err)
+	equals(t, "404: Not found (Unknown guild)", err.Error())
 }
 
 func TestSetBalanceWithNonInfiniteData(t *testing.T) {
@@ -273,7 +282,7 @@ func TestSetBalanceWithNonInfiniteData(t *testing.T) {
     api := Custom("token", client)
 	data, err := api.SetBalance("411898639737421824", "398197113495748626", 50, 502, "Just testing")
 	ok(t, err)
-	equals(t, userObj{0,"398197113495748626",50,false,false,502,false,false,552,false,false}, data)
+	equals(t, Balance{0, "398197113495748626", NewAmount(50), NewAmount(502), NewAmount(552)}, data)
 }
 
 func TestSetBalanceWithOnlyCashInfiniteData(t *testing.T) {
@@ -282,7 +291,7 @@ func TestSetBalanceWithOnlyCashInfiniteData(t *testing.T) {
     api := Custom("token", client)
 	data, err := api.SetBalance("411898639737421824", "398197113495748626", "Infinity", 502, "Just testing")
 	ok(t, err)
-	equals(t, userObj{0,"398197113495748626",0,true,false,502,false,false,0,true,false}, data)
+	equals(t, Balance{0, "398197113495748626", Inf(1), NewAmount(502), Inf(1)}, data)
 }
 
 func TestSetBalanceWithOnlyBankInfiniteData(t *testing.T) {
@@ -291,7 +300,7 @@ func TestSetBalanceWithOnlyBankInfiniteData(t *testing.T) {
     api := Custom("token", client)
 	data, err := api.SetBalance("411898639737421824", "398197113495748626", 50, "Infinity", "Just testing")
 	ok(t, err)
-	equals(t, userObj{0,"398197113495748626",50,false,false,0,true,false,0,true,false}, data)
+	equals(t, Balance{0, "398197113495748626", NewAmount(50), Inf(1), Inf(1)}, data)
 }
 
 func TestSetBalanceWithOnlyCashNegitiveInfiniteData(t *testing.T) {
@@ -300,7 +309,7 @@ func TestSetBalanceWithOnlyCashNegitiveInfiniteData(t *testing.T) {
     api := Custom("token", client)
 	data, err := api.SetBalance("411898639737421824", "398197113495748626", "Infinity", 502, "Just testing")
 	ok(t, err)
-	equals(t, userObj{0,"398197113495748626",0,false,true,502,false,false,0,false,true}, data)
+	equals(t, Balance{0, "398197113495748626", Inf(-1), NewAmount(502), Inf(-1)}, data)
 }
 
 func TestSetBalanceWithOnlyBankNegitiveInfiniteData(t *testing.T) {
@@ -309,7 +318,7 @@ func TestSetBalanceWithOnlyBankNegitiveInfiniteData(t *testing.T) {
     api := Custom("token", client)
 	data, err := api.SetBalance("411898639737421824", "398197113495748626", 50, "Infinity", "Just testing")
 	ok(t, err)
-	equals(t, userObj{0,"398197113495748626",50,false,false,0,false,true,0,false,true}, data)
+	equals(t, Balance{0, "398197113495748626", NewAmount(50), Inf(-1), Inf(-1)}, data)
 }
 
 func TestSetBalanceWithAllInfiniteData(t *testing.T) {
@@ -318,7 +327,7 @@ func TestSetBalanceWithAllInfiniteData(t *testing.T) {
     api := Custom("token", client)
 	data, err := api.SetBalance("411898639737421824", "398197113495748626", "Infinity", "Infinity", "Just testing")
 	ok(t, err)
-	equals(t, userObj{0,"398197113495748626",0,true,false,0,true,false,0,true,false}, data)
+	equals(t, Balance{0, "398197113495748626", Inf(1), Inf(1), Inf(1)}, data)
 }
 
 func TestSetBalanceWithAllNegitiveInfiniteData(t *testing.T) {
@@ -327,7 +336,7 @@ func TestSetBalanceWithAllNegitiveInfiniteData(t *testing.T) {
     api := Custom("token", client)
 	data, err := api.SetBalance("411898639737421824", "398197113495748626", "-Infinity", "-Infinity", "Just testing")
 	ok(t, err)
-	equals(t, userObj{0,"398197113495748626",0,false,true,0,false,true,0,false,true}, data)
+	equals(t, Balance{0, "398197113495748626", Inf(-1), Inf(-1), Inf(-1)}, data)
 }
 
 func TestSetBalanceWithCashInfiniteCashNegitiveInfinite(t *testing.T) {
@@ -336,7 +345,7 @@ func TestSetBalanceWithCashInfiniteCashNegitiveInfinite(t *testing.T) {
     api := Custom("token", client)
 	data, err := api.SetBalance("411898639737421824", "398197113495748626", "-Infinity", "Infinity", "Just testing")
 	ok(t, err)
-	equals(t, userObj{0,"398197113495748626",0,false,true,0,true,false,0,false,false}, data)
+	equals(t, Balance{0, "398197113495748626", Inf(-1), Inf(1), NewAmount(0)}, data)
 }
 
 func TestSetBalanceWithCashInfiniteBankNegitiveInfinite(t *testing.T) {
@@ -345,7 +354,7 @@ func TestSetBalanceWithCashInfiniteBankNegitiveInfinite(t *testing.T) {
     api := Custom("token", client)
 	data, err := api.SetBalance("411898639737421824", "398197113495748626", "Infinity", "-Infinity", "Just testing")
 	ok(t, err)
-	equals(t, userObj{0,"398197113495748626",0,true,false,0,false,true,0,false,false}, data)
+	equals(t, Balance{0, "398197113495748626", Inf(1), Inf(-1), NewAmount(0)}, data)
 }
 
 func TestUpdateBalanceWithCorrectData(t *testing.T) {
@@ -354,7 +363,7 @@ func TestUpdateBalanceWithCorrectData(t *testing.T) {
     api := Custom("token", client)
 	data, err := api.UpdateBalance("411898639737421824", "398197113495748626", 0, 0, "Just testing")
 	ok(t, err)
-	equals(t, userObj{0,"398197113495748626",50,false,false,502,false,false,552,false,false}, data)
+	equals(t, Balance{0, "398197113495748626", NewAmount(50), NewAmount(502), NewAmount(552)}, data)
 }
 
 func TestUpdateBalanceWithNegitiveData(t *testing.T) {
@@ -363,7 +372,106 @@ func TestUpdateBalanceWithNegitiveData(t *testing.T) {
     api := Custom("token", client)
 	data, err := api.UpdateBalance("411898639737421824", "398197113495748626", -40, -980, "Just testing")
 	ok(t, err)
-	equals(t, userObj{0,"398197113495748626",50,false,false,502,false,false,552,false,false}, data)
+	equals(t, Balance{0, "398197113495748626", NewAmount(50), NewAmount(502), NewAmount(552)}, data)
+}
+
+// Blocks until the request's context is done, like a server that never answers.
+func setHangingClient() *http.Client {
+	return NewTestClient(func(req *http.Request) *http.Response {
+		<-req.Context().Done()
+		return nil
+	})
+}
+
+func TestGetBalanceContextReturnsDeadlineExceeded(t *testing.T) {
+	api := Custom("token", setHangingClient())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	data, err := api.GetBalanceContext(ctx, "411898639737421824", "398197113495748626")
+	equals(t, Balance{}, data)
+	equals(t, context.DeadlineExceeded, err)
+}
+
+func TestLeaderboardContextReturnsCanceled(t *testing.T) {
+	api := Custom("token", setHangingClient())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	data, err := api.LeaderboardContext(ctx, "411898639737421824")
+	equals(t, []LeaderboardEntry{}, data)
+	equals(t, context.Canceled, err)
+}
+
+func TestCheckContextReturnsIsDownOnDeadline(t *testing.T) {
+	api := Custom("token", setHangingClient())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	check, err := api.CheckContext(ctx)
+	assert(t, errors.Is(err, context.DeadlineExceeded), "expected deadline exceeded, got %v", err)
+	equals(t, false, check.Up)
 }
 
+func TestRequestContextPassesContextToTransport(t *testing.T) {
+	type key struct{}
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		equals(t, "value", req.Context().Value(key{}))
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"user_id":"398197113495748626","cash":50,"bank":502,"total":552}`)),
+			Header:     make(http.Header),
+		}
+	})
+
+	api := Custom("token", client)
+	ctx := context.WithValue(context.Background(), key{}, "value")
+	data, err := api.UpdateBalanceContext(ctx, "411898639737421824", "398197113495748626", 10, 0, nil)
+	ok(t, err)
+	equals(t, NewAmount(552), data.Total)
+}
 
+func TestGetBalanceIgnoresTheWordErrorOutsideAnErrorBody(t *testing.T) {
+	client := setClient(200, "/guilds/411898639737421824/users/398197113495748626", `{"user_id":"398197113495748626","cash":25,"bank":200,"total":225,"reason":"error correction"}`)
+
+	api := Custom("token", client)
+	data, err := api.GetBalance("411898639737421824", "398197113495748626")
+	ok(t, err)
+	equals(t, NewAmount(225), data.Total)
+}
+
+func TestGetBalanceReturnsAPIErrorOnStatusWithoutPanickingOnMalformedBody(t *testing.T) {
+	client := setClient(502, "/guilds/411898639737421824/users/398197113495748626", `<html>Bad Gateway</html>`)
+
+	api := Custom("token", client)
+	data, err := api.GetBalance("411898639737421824", "398197113495748626")
+	equals(t, Balance{}, data)
+	equals(t, &APIError{StatusCode: 502, Body: []byte(`<html>Bad Gateway</html>`)}, err)
+	equals(t, "502: Bad Gateway", err.Error())
+}
+
+func TestGetBalanceReturnsAPIErrorStatusFromResponse(t *testing.T) {
+	client := setClient(403, "/guilds/411898639737421824/users/398197113495748626", `{"error":"403: Forbidden","message":"Missing Permissions"}`)
+
+	api := Custom("token", client)
+	_, err := api.GetBalance("411898639737421824", "398197113495748626")
+	var apiErr *APIError
+	assert(t, errors.As(err, &apiErr), "expected *APIError, got %#v", err)
+	equals(t, 403, apiErr.StatusCode)
+	equals(t, "Missing Permissions", apiErr.Message)
+}
+
+func TestRateLimitFallsBackToRetryAfterHeaderOnMalformedBody(t *testing.T) {
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		header := make(http.Header)
+		header.Set("Retry-After", "2")
+		return &http.Response{
+			StatusCode: 429,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`Too Many Requests`)),
+			Header:     header,
+		}
+	})
+
+	api := Custom("token", client)
+	_, err := api.GetBalance("411898639737421824", "398197113495748626")
+	var rateErr *RateLimitError
+	assert(t, errors.As(err, &rateErr), "expected *RateLimitError, got %#v", err)
+	equals(t, 2*time.Second, rateErr.RetryAfter)
+}